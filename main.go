@@ -109,20 +109,54 @@ func run(ctx context.Context) error {
 		cfg.ApplyHTTP,
 	)
 
+	if env("DISABLE_VCARD", "false") == "false" {
+		app, err := app_vcard.New(ctx, &xmpp.Config{
+			Jid:        os.Getenv("XMPP_USERNAME"),
+			Credential: xmpp.Password(os.Getenv("XMPP_PASSWORD")),
+		})
+		if err != nil {
+			return err
+		}
+		app.Routes(mux)
+
+		// Verify xmpp proofs over the local connection unless pointed at a remote host.
+		if os.Getenv("XMPP_URL") == "" {
+			ctx = app_keyproofs.WithVCard(ctx, app)
+		}
+	}
+
 	if env("DISABLE_KEYPROOF", "false") == "false" {
 		// Set config values
+		// dns and xmpp proofs are checked against BASE_URL unless set apart.
 		cfg.Set("base-url", env("BASE_URL", baseURL))
-		cfg.Set("dns-url", env("DNS_URL", baseURL))
-		cfg.Set("xmpp-url", env("XMPP_URL", baseURL))
+		cfg.Set("dns-url", env("DNS_URL", cfg.GetString("base-url")))
+		cfg.Set("xmpp-url", env("XMPP_URL", cfg.GetString("base-url")))
 
 		cfg.Set("reddit.api-key", os.Getenv("REDDIT_APIKEY"))
 		cfg.Set("reddit.secret", os.Getenv("REDDIT_SECRET"))
+		cfg.Set("reddit.token-url", env("REDDIT_TOKEN_URL", "https://www.reddit.com/api/v1/access_token"))
+		cfg.Set("reddit.api-url", os.Getenv("REDDIT_API_URL"))
+		cfg.Set("twitter.bearer-token", os.Getenv("TWITTER_BEARER_TOKEN"))
+		cfg.Set("twitter.nitter", os.Getenv("TWITTER_NITTER"))
+		cfg.Set("twitter.backends", os.Getenv("TWITTER_BACKENDS"))
+		cfg.Set("hackernews.api-url", os.Getenv("HACKERNEWS_API_URL"))
 		cfg.Set("github.secret", os.Getenv("GITHUB_SECRET"))
+		cfg.Set("gitlab.tokens", os.Getenv("GITLAB_TOKENS"))
+		cfg.Set("proofs.disabled", os.Getenv("DISABLE_PROOFS"))
+		cfg.Set("matrix.homeserver", os.Getenv("MATRIX_HOMESERVER"))
+		cfg.Set("matrix.access-token", os.Getenv("MATRIX_ACCESS_TOKEN"))
+		cfg.Set("key-sources", env("KEY_SOURCES", "wkd vks"))
+		cfg.Set("key-sources.wait", os.Getenv("KEY_SOURCES_WAIT"))
 
 		// Create cache for promise engine
 		arc, _ := lru.NewARC(4096)
 		c := cache.New(arc)
-		app_keyproofs.NewKeyProofApp(ctx, c).Routes(mux)
+		app, err := app_keyproofs.NewKeyProofApp(ctx, c)
+		if err != nil {
+			return err
+		}
+
+		app.Routes(mux)
 	}
 
 	if env("DISABLE_DNS", "false") == "false" {
@@ -147,17 +181,6 @@ func run(ctx context.Context) error {
 		app.Routes(mux)
 	}
 
-	if env("DISABLE_VCARD", "false") == "false" {
-		app, err := app_vcard.New(ctx, &xmpp.Config{
-			Jid:        os.Getenv("XMPP_USERNAME"),
-			Credential: xmpp.Password(os.Getenv("XMPP_PASSWORD")),
-		})
-		if err != nil {
-			return err
-		}
-		app.Routes(mux)
-	}
-
 	log.Info().
 		Str("listen", listen).
 		Int("user", os.Geteuid()).
@@ -0,0 +1,135 @@
+package app_keyproofs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var ErrActorMismatch = errors.New("actor does not match proof url")
+
+type fediverseResolve struct {
+	proof Proof
+	user  string
+}
+
+type activityActor struct {
+	ID                string          `json:"id"`
+	URL               json.RawMessage `json:"url"`
+	PreferredUsername string          `json:"preferredUsername"`
+	Summary           string          `json:"summary"`
+	Attachment        []struct {
+		Type  string `json:"type"`
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	} `json:"attachment"`
+}
+
+// URLs returns the canonical urls of the actor.
+func (a *activityActor) URLs() []string {
+	lis := []string{a.ID}
+
+	var s string
+	if err := json.Unmarshal(a.URL, &s); err == nil {
+		return append(lis, s)
+	}
+
+	var arr []string
+	if err := json.Unmarshal(a.URL, &arr); err == nil {
+		return append(lis, arr...)
+	}
+
+	return lis
+}
+
+func (r *fediverseResolve) Resolve(ctx context.Context) error {
+	uri := r.proof.URI
+	actorURL := r.proof.Verify
+
+	if href, err := webfingerActor(ctx, r.user, uri.Host); err == nil && href != "" {
+		actorURL = href
+	}
+
+	actor := activityActor{}
+	hdr := map[string]string{"Accept": "application/activity+json"}
+	if err := httpJSON(ctx, actorURL, hdr, &actor); err != nil {
+		return r.proof.setStatus(err)
+	}
+
+	if actor.PreferredUsername != "" {
+		r.proof.Name = fmt.Sprintf("%s@%s", actor.PreferredUsername, uri.Host)
+	}
+
+	if !matchURL(r.proof.Verify, actor.URLs()...) {
+		return r.proof.setStatus(ErrActorMismatch)
+	}
+
+	fields := []string{htmlText(actor.Summary)}
+	for _, a := range actor.Attachment {
+		if a.Type == "PropertyValue" {
+			fields = append(fields, htmlText(a.Value))
+		}
+	}
+
+	return r.proof.setStatus(checkClaim(r.proof.Fingerprint, fields))
+}
+func (r *fediverseResolve) Proof() *Proof {
+	return &r.proof
+}
+
+func webfingerActor(ctx context.Context, user, host string) (string, error) {
+	wf := struct {
+		Links []struct {
+			Rel  string `json:"rel"`
+			Type string `json:"type"`
+			Href string `json:"href"`
+		} `json:"links"`
+	}{}
+
+	resource := url.QueryEscape(fmt.Sprintf("acct:%s@%s", user, host))
+	uri := fmt.Sprintf("https://%s/.well-known/webfinger?resource=%s", host, resource)
+	if err := httpJSON(ctx, uri, nil, &wf); err != nil {
+		return "", err
+	}
+
+	for _, link := range wf.Links {
+		if link.Rel == "self" && (link.Type == "application/activity+json" ||
+			strings.HasPrefix(link.Type, "application/ld+json")) {
+			return link.Href, nil
+		}
+	}
+
+	return "", nil
+}
+
+func matchURL(claim string, urls ...string) bool {
+	a, err := url.Parse(claim)
+	if err != nil {
+		return false
+	}
+
+	for _, s := range urls {
+		b, err := url.Parse(s)
+		if err != nil {
+			continue
+		}
+		if strings.EqualFold(a.Host, b.Host) &&
+			strings.TrimSuffix(a.Path, "/") == strings.TrimSuffix(b.Path, "/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+// htmlText strips tags and decodes entities.
+func htmlText(s string) string {
+	return html.UnescapeString(htmlTag.ReplaceAllString(s, " "))
+}
@@ -0,0 +1,124 @@
+package app_keyproofs
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+	zlog "github.com/rs/zerolog/log"
+
+	"github.com/sour-is/keyproofs/pkg/opgp"
+	"github.com/sour-is/keyproofs/pkg/opgp/entity"
+)
+
+type apiAddress struct {
+	Name    string `json:"name"`
+	Address string `json:"address"`
+}
+
+type apiEntity struct {
+	Fingerprint   string            `json:"fingerprint"`
+	Primary       *apiAddress       `json:"primary"`
+	Emails        []*apiAddress     `json:"emails"`
+	SelfSignature *time.Time        `json:"self_signature,omitempty"`
+	Identities    []*apiIdentity    `json:"identities"`
+	Key           *entity.KeyInfo   `json:"key,omitempty"`
+	Subkeys       []*entity.KeyInfo `json:"subkeys,omitempty"`
+	Warnings      []string          `json:"warnings,omitempty"`
+	Verified      bool              `json:"verified"`
+	Source        string            `json:"source,omitempty"`
+	ArmorText     string            `json:"armor"`
+}
+
+type apiIdentity struct {
+	Name          string     `json:"name"`
+	SelfSignature *time.Time `json:"self_signature,omitempty"`
+	Verified      bool       `json:"verified"`
+	Proofs        []string   `json:"proofs"`
+}
+
+type apiProofs struct {
+	ID         string     `json:"id"`
+	Entity     *apiEntity `json:"entity,omitempty"`
+	Proofs     []*Proof   `json:"proofs"`
+	IsComplete bool       `json:"complete"`
+	Err        string     `json:"error,omitempty"`
+}
+
+func newAPIEntity(e *entity.Entity) *apiEntity {
+	a := &apiEntity{
+		Fingerprint: e.Fingerprint,
+		Primary:     &apiAddress{e.Primary.Name, e.Primary.Address},
+		Emails:      make([]*apiAddress, len(e.Emails)),
+		Key:         e.Key,
+		Subkeys:     e.Subkeys,
+		Warnings:    e.Warnings(),
+		Verified:    e.Verified(),
+		Source:      e.Source,
+		ArmorText:   e.ArmorText,
+	}
+	for i, email := range e.Emails {
+		a.Emails[i] = &apiAddress{email.Name, email.Address}
+	}
+	if e.SelfSignature != nil {
+		a.SelfSignature = &e.SelfSignature.CreationTime
+	}
+	for _, id := range e.Identities {
+		item := &apiIdentity{Name: id.Name, Verified: id.Verified, Proofs: id.Proofs}
+		if id.SelfSignature != nil {
+			item.SelfSignature = &id.SelfSignature.CreationTime
+		}
+		if item.Proofs == nil {
+			item.Proofs = []string{}
+		}
+		a.Identities = append(a.Identities, item)
+	}
+
+	return a
+}
+
+func (app *keyproofApp) getProofsJSON(w http.ResponseWriter, r *http.Request) {
+	log := zlog.Ctx(r.Context())
+
+	id := chi.URLParam(r, "id")
+	log.Debug().Str("get ", id).Send()
+
+	// Setup timeout for the entity to resolve
+	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+	defer cancel()
+
+	res := apiProofs{ID: id, Proofs: []*Proof{}}
+
+	e, err := app.resolve(ctx, id)
+	if err != nil {
+		res.Err = err.Error()
+		res.IsComplete = true
+		code := http.StatusNotFound
+		if errors.Is(err, opgp.ErrInvalidID) {
+			code = http.StatusBadRequest
+		}
+		writeJSON(w, code, res)
+		return
+	}
+
+	if e == nil {
+		writeJSON(w, http.StatusAccepted, res)
+		return
+	}
+
+	res.Entity = newAPIEntity(e)
+
+	var proofs Proofs
+	_, _, proofs, res.IsComplete = app.collect(ctx, e)
+	for _, p := range e.Proofs {
+		res.Proofs = append(res.Proofs, proofs[p])
+	}
+
+	code := http.StatusOK
+	if !res.IsComplete {
+		code = http.StatusAccepted
+	}
+	writeJSON(w, code, res)
+}
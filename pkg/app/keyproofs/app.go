@@ -1,8 +1,11 @@
 package app_keyproofs
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
 	"net/http"
@@ -39,21 +42,42 @@ var defaultStyle = &style.Style{
 type keyproofApp struct {
 	cache  cache.Cacher
 	tasker promise.Tasker
+	keys   opgp.KeySource
 }
 
-func NewKeyProofApp(ctx context.Context, c cache.Cacher) *keyproofApp {
+func NewKeyProofApp(ctx context.Context, c cache.Cacher) (*keyproofApp, error) {
+	log := zlog.Ctx(ctx)
+	log.Debug().Strs("services", services.Services()).Msg("proof services")
+
+	cfg := config.FromContext(ctx)
+
+	sources, err := opgp.ParseSources(cfg.GetString("key-sources"))
+	if err != nil {
+		return nil, err
+	}
+	wait := opgp.DefaultWait
+	if s := cfg.GetString("key-sources.wait"); s != "" {
+		if wait, err = time.ParseDuration(s); err != nil {
+			return nil, fmt.Errorf("key sources wait: %w", err)
+		}
+	}
+	log.Debug().Str("sources", sources.Name()).Dur("wait", wait).Msg("key sources")
+
 	return &keyproofApp{
 		cache: c,
+		keys:  opgp.Parallel{Sources: sources, Wait: wait},
 		tasker: promise.NewRunner(
 			ctx,
 			promise.Timeout(runnerTimeout),
 			promise.WithCache(c, expireAfter),
 		),
-	}
+	}, nil
 }
 func (app *keyproofApp) Routes(r *chi.Mux) {
 	r.MethodFunc("GET", "/", app.getHome)
 	r.MethodFunc("GET", "/id/{id}", app.getProofs)
+	r.MethodFunc("GET", "/id/{id}/events", app.getProofEvents)
+	r.MethodFunc("GET", "/api/v1/id/{id}", app.getProofsJSON)
 	r.MethodFunc("GET", "/qr", app.getQR)
 	r.MethodFunc("GET", "/favicon.ico", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "image/png")
@@ -72,14 +96,86 @@ func (app *keyproofApp) getProofs(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
 	defer cancel()
 
-	// Run tasks to resolve entity, style, and proofs.
+	page := page{Style: defaultStyle}
+	page.AppName = fmt.Sprintf("%s v%s", cfg.GetString("app-name"), cfg.GetString("app-version"))
+	page.AppBuild = fmt.Sprintf("%s %s", cfg.GetString("build-date"), cfg.GetString("build-hash"))
+
+	code := http.StatusOK
+	page.Entity, page.Err = app.resolve(ctx, id)
+	if page.Err != nil {
+		page.IsComplete = true
+	}
+	if errors.Is(page.Err, opgp.ErrInvalidID) {
+		code = http.StatusBadRequest
+	}
+
+	// Build page based on available information.
+	if page.Entity != nil {
+		var gotStyle bool
+		var proofs Proofs
+
+		page.Style, gotStyle, proofs, page.IsComplete = app.collect(ctx, page.Entity)
+		page.Photo = photoURL(page.Entity)
+		if len(proofs) > 0 {
+			page.HasProofs = true
+			page.Proofs = &proofs
+			page.Identities = newPageIdentities(page.Entity, proofs)
+		}
+		if !gotStyle {
+			page.Style = defaultStyle
+		}
+	}
+
+	// Template and display.
+	var err error
+	t := template.New("page")
+	t, err = t.Parse(pageTPL)
+	if err != nil {
+		writeText(w, 500, err.Error())
+		return
+	}
+
+	t, err = t.Parse(proofTPL)
+	if err != nil {
+		writeText(w, 500, err.Error())
+		return
+	}
+
+	t, err = t.Parse(eventsTPL)
+	if err != nil {
+		writeText(w, 500, err.Error())
+		return
+	}
+
+	var buf bytes.Buffer
+	err = t.Execute(&buf, page)
+	if err != nil {
+		writeText(w, 500, err.Error())
+		return
+	}
+
+	w.WriteHeader(code)
+	_, _ = buf.WriteTo(w)
+}
+
+// resolve runs the tasks to resolve entity, style and proofs for id. It waits for
+// the entity until ctx is done and falls back to any cached value.
+func (app *keyproofApp) resolve(ctx context.Context, id string) (*entity.Entity, error) {
+	log := zlog.Ctx(ctx)
+
+	// Malformed ids fail early and hex ids share a cache key in any form.
+	_, id, err := opgp.ParseID(id)
+	if err != nil {
+		return nil, err
+	}
+
 	task := app.tasker.Run(entity.Key(id), func(q promise.Q) {
 		ctx := q.Context()
 		log := zlog.Ctx(ctx).With().Interface(fmtKey(q), q.Key()).Logger()
 
 		key := q.Key().(entity.Key)
 
-		e, err := opgp.GetKey(ctx, string(key))
+		e, err := app.keys.GetKey(ctx, string(key))
 		if err != nil {
 			q.Reject(err)
 			return
@@ -98,22 +194,7 @@ func (app *keyproofApp) getProofs(w http.ResponseWriter, r *http.Request) {
 			Interface(fmtKey(q), q.Key()).
 			Msg("Do Style ")
 
-		q.Run(style.Key(entity.Primary.Address), func(q promise.Q) {
-			ctx := q.Context()
-			log := zlog.Ctx(ctx).With().Interface(fmtKey(q), q.Key()).Logger()
-
-			key := q.Key().(style.Key)
-
-			log.Debug().Msg("start task")
-			style, err := style.GetStyle(ctx, string(key))
-			if err != nil {
-				q.Reject(err)
-				return
-			}
-
-			log.Debug().Msg("Resolving Style")
-			q.Resolve(style)
-		})
+		q.Run(style.Key(entity.Primary.Address), styleTask)
 	})
 
 	task.After(func(q promise.ResultQ) {
@@ -128,99 +209,95 @@ func (app *keyproofApp) getProofs(w http.ResponseWriter, r *http.Request) {
 			Msg("Scheduling Proofs")
 
 		for i := range entity.Proofs {
-			q.Run(ProofKey(entity.Proofs[i]), func(q promise.Q) {
-				ctx := q.Context()
-				log := zlog.Ctx(ctx).
-					With().
-					Interface(fmtKey(q), q.Key()).
-					Logger()
-
-				key := q.Key().(ProofKey)
-				proof := NewProof(ctx, string(key), entity.Fingerprint)
-				defer log.Debug().Interface("status", proof.Proof().Status).Msg("Resolving Proof")
-
-				if err := proof.Resolve(ctx); err != nil && err != ErrNoFingerprint {
-					log.Err(err).Send()
-				}
-
-				q.Resolve(proof.Proof())
-			})
+			uri := entity.Proofs[i]
+			q.Run(ProofKey(uri), proofTask(entity.Fingerprint, entity.Notations[uri]))
 		}
 	})
 
-	page := page{Style: defaultStyle}
-	page.AppName = fmt.Sprintf("%s v%s", cfg.GetString("app-name"), cfg.GetString("app-version"))
-	page.AppBuild = fmt.Sprintf("%s %s", cfg.GetString("build-date"), cfg.GetString("build-hash"))
-
 	// Wait for either entity to resolve or timeout
 	select {
 	case <-task.Await():
 		log.Print("Tasks Competed")
 		if err := task.Err(); err != nil {
-			page.Err = err
-			page.IsComplete = true
-			break
+			return nil, err
 		}
-		page.Entity = task.Result().(*entity.Entity)
+		return task.Result().(*entity.Entity), nil
 
 	case <-ctx.Done():
 		log.Print("Deadline Timeout")
 		if e, ok := app.cache.Get(entity.Key(id)); ok {
-			page.Entity = e.Value().(*entity.Entity)
+			return e.Value().(*entity.Entity), nil
 		}
 	}
 
-	// Build page based on available information.
-	if page.Entity != nil {
-		var gotStyle, gotProofs bool
-
-		if s, ok := app.cache.Get(style.Key(page.Entity.Primary.Address)); ok {
-			page.Style = s.Value().(*style.Style)
-			gotStyle = true
-		}
+	return nil, nil
+}
 
-		gotProofs = true
-		if len(page.Entity.Proofs) > 0 {
-			page.HasProofs = true
-			proofs := make(Proofs, len(page.Entity.Proofs))
-			for i := range page.Entity.Proofs {
-				p := page.Entity.Proofs[i]
-
-				if s, ok := app.cache.Get(ProofKey(p)); ok {
-					log.Debug().Str("uri", p).Msg("Proof from cache")
-					proofs[p] = s.Value().(*Proof)
-				} else {
-					log.Debug().Str("uri", p).Msg("Missing proof")
-					proofs[p] = NewProof(ctx, p, page.Entity.Fingerprint).Proof()
-					gotProofs = false
-				}
-			}
-			page.Proofs = &proofs
-		}
+func styleTask(q promise.Q) {
+	ctx := q.Context()
+	log := zlog.Ctx(ctx).With().Interface(fmtKey(q), q.Key()).Logger()
 
-		page.IsComplete = gotStyle && gotProofs
-	}
+	key := q.Key().(style.Key)
 
-	// Template and display.
-	var err error
-	t := template.New("page")
-	t, err = t.Parse(pageTPL)
+	log.Debug().Msg("start task")
+	style, err := style.GetStyle(ctx, string(key))
 	if err != nil {
-		writeText(w, 500, err.Error())
+		q.Reject(err)
 		return
 	}
 
-	t, err = t.Parse(proofTPL)
-	if err != nil {
-		writeText(w, 500, err.Error())
-		return
+	log.Debug().Msg("Resolving Style")
+	q.Resolve(style)
+}
+
+func proofTask(fingerprint, notation string) promise.Fn {
+	return func(q promise.Q) {
+		ctx := q.Context()
+		log := zlog.Ctx(ctx).
+			With().
+			Interface(fmtKey(q), q.Key()).
+			Logger()
+
+		key := q.Key().(ProofKey)
+		proof := NewProof(ctx, string(key), fingerprint)
+		proof.Proof().Notation = notation
+		defer log.Debug().Interface("status", proof.Proof().Status).Msg("Resolving Proof")
+
+		if err := proof.Resolve(ctx); err != nil && !isInvalid(err) {
+			log.Err(err).Send()
+		}
+
+		q.Resolve(proof.Proof())
 	}
+}
 
-	err = t.Execute(w, page)
-	if err != nil {
-		writeText(w, 500, err.Error())
-		return
+// collect reads the style and proofs of an entity from cache. Proofs that are not
+// resolved yet are returned with a checking status.
+func (app *keyproofApp) collect(ctx context.Context, e *entity.Entity) (s *style.Style, gotStyle bool, proofs Proofs, complete bool) {
+	log := zlog.Ctx(ctx)
+
+	if v, ok := app.cache.Get(style.Key(e.Primary.Address)); ok {
+		s = v.Value().(*style.Style)
+		gotStyle = true
+	}
+
+	gotProofs := true
+	proofs = make(Proofs, len(e.Proofs))
+	for i := range e.Proofs {
+		p := e.Proofs[i]
+
+		if v, ok := app.cache.Get(ProofKey(p)); ok {
+			log.Debug().Str("uri", p).Msg("Proof from cache")
+			proofs[p] = v.Value().(*Proof)
+		} else {
+			log.Debug().Str("uri", p).Msg("Missing proof")
+			proofs[p] = NewProof(ctx, p, e.Fingerprint).Proof()
+			proofs[p].Notation = e.Notations[p]
+			gotProofs = false
+		}
 	}
+
+	return s, gotStyle, proofs, gotStyle && gotProofs
 }
 func (app *keyproofApp) getHome(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -251,6 +328,12 @@ func (app *keyproofApp) getHome(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	t, err = t.Parse(eventsTPL)
+	if err != nil {
+		writeText(w, 500, err.Error())
+		return
+	}
+
 	err = t.Execute(w, page)
 	if err != nil {
 		writeText(w, 500, err.Error())
@@ -309,6 +392,13 @@ func writeText(w http.ResponseWriter, code int, o string) {
 	_, _ = w.Write([]byte(o))
 }
 
+// WriteJSON writes json encoded value
+func writeJSON(w http.ResponseWriter, code int, o interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(o)
+}
+
 func fmtKey(key promise.Key) string {
 	return fmt.Sprintf("%T", key.Key())
 }
@@ -0,0 +1,173 @@
+package app_keyproofs
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strings"
+)
+
+var ErrNoFingerprint = errors.New("fingerprint not found")
+var ErrClaimFormat = errors.New("fingerprint found but not in openpgp4fpr claim format")
+var ErrNotOwnerField = errors.New("fingerprint found but not in owner-controlled field")
+
+// invalidErrs are the errors that mark a proof invalid rather than failed.
+var invalidErrs = []error{
+	ErrNoFingerprint,
+	ErrClaimFormat,
+	ErrNotOwnerField,
+	ErrActorMismatch,
+	ErrSenderMismatch,
+	ErrOwnerMismatch,
+}
+
+func isInvalid(err error) bool {
+	for _, e := range invalidErrs {
+		if errors.Is(err, e) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// setStatus sets the proof status and reason from the result of a check.
+func (p *Proof) setStatus(err error) error {
+	switch {
+	case err == nil:
+		p.Status = ProofVerified
+		p.Reason = ""
+	case errors.Is(err, ErrRateLimited):
+		p.Status = ProofRateLimited
+		p.Reason = err.Error()
+	case isInvalid(err):
+		p.Status = ProofInvalid
+		p.Reason = err.Error()
+	default:
+		p.Status = ProofError
+		p.Reason = err.Error()
+	}
+
+	return err
+}
+
+// matchClaim looks for the openpgp4fpr:<fingerprint> claim in text.
+func matchClaim(text, fingerprint string) error {
+	text = strings.ToUpper(text)
+	fingerprint = strings.ToUpper(fingerprint)
+	claim := "OPENPGP4FPR:" + fingerprint
+
+	for i := strings.Index(text, claim); i >= 0; {
+		end := i + len(claim)
+		if end == len(text) || !isHex(text[end]) {
+			return nil
+		}
+
+		n := strings.Index(text[end:], claim)
+		if n < 0 {
+			break
+		}
+		i = end + n
+	}
+
+	if strings.Contains(text, fingerprint) {
+		return ErrClaimFormat
+	}
+
+	return ErrNoFingerprint
+}
+
+// checkClaim requires the claim to be in one of the owner fields. The remaining
+// content is only used to explain why a proof failed.
+func checkClaim(fingerprint string, owner []string, rest ...string) error {
+	err := ErrNoFingerprint
+	for _, field := range owner {
+		switch e := matchClaim(field, fingerprint); e {
+		case nil:
+			return nil
+		case ErrClaimFormat:
+			err = e
+		}
+	}
+
+	if err == ErrNoFingerprint {
+		for _, field := range rest {
+			if matchClaim(field, fingerprint) != ErrNoFingerprint {
+				return ErrNotOwnerField
+			}
+		}
+	}
+
+	return err
+}
+
+func isHex(c byte) bool {
+	return ('0' <= c && c <= '9') || ('A' <= c && c <= 'F') || ('a' <= c && c <= 'f')
+}
+
+// fieldFn extracts the owner-controlled fields from a response body.
+type fieldFn func([]byte) []string
+
+// textLines treats each line of the body as an owner field.
+func textLines(body []byte) []string {
+	return strings.Split(string(body), "\n")
+}
+
+// jsonFields reads fields from a json body using dotted paths. A * in the path
+// matches all items of an array or object.
+func jsonFields(paths ...string) fieldFn {
+	return func(body []byte) []string {
+		var doc interface{}
+		if err := json.Unmarshal(body, &doc); err != nil {
+			return nil
+		}
+
+		var lis []string
+		for _, path := range paths {
+			lis = append(lis, walkJSON(doc, strings.Split(path, "."))...)
+		}
+		return lis
+	}
+}
+
+func walkJSON(v interface{}, path []string) []string {
+	if len(path) == 0 {
+		if s, ok := v.(string); ok {
+			return []string{s}
+		}
+		return nil
+	}
+
+	var lis []string
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if path[0] == "*" {
+			for _, item := range v {
+				lis = append(lis, walkJSON(item, path[1:])...)
+			}
+		} else if item, ok := v[path[0]]; ok {
+			lis = append(lis, walkJSON(item, path[1:])...)
+		}
+	case []interface{}:
+		if path[0] == "*" {
+			for _, item := range v {
+				lis = append(lis, walkJSON(item, path[1:])...)
+			}
+		}
+	}
+
+	return lis
+}
+
+// htmlFields reads the first submatch of re from an html body as text.
+func htmlFields(re *regexp.Regexp) fieldFn {
+	return func(body []byte) []string {
+		var lis []string
+		for _, m := range re.FindAllSubmatch(body, -1) {
+			lis = append(lis, htmlText(string(m[1])))
+		}
+		return lis
+	}
+}
+
+var ogDescription = regexp.MustCompile(`<meta\s+property="og:description"\s+content="([^"]*)"`)
@@ -0,0 +1,56 @@
+package app_keyproofs
+
+import "testing"
+
+const testFingerprint = "3E0F4D5BA4FDAE3B0A1D3E9BB5B8F1C0D4A6E2F7"
+
+func TestMatchClaim(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		err  error
+	}{
+		{"exact claim", "openpgp4fpr:" + testFingerprint, nil},
+		{"lower case claim", "OpenPGP4FPR:3e0f4d5ba4fdae3b0a1d3e9bb5b8f1c0d4a6e2f7", nil},
+		{"claim in text", "my key [openpgp4fpr:" + testFingerprint + "] is here", nil},
+		{"claim followed by hex", "openpgp4fpr:" + testFingerprint + "AB", ErrClaimFormat},
+		{"longer claim then exact claim", "openpgp4fpr:" + testFingerprint + "AB openpgp4fpr:" + testFingerprint, nil},
+		{"bare fingerprint", "key " + testFingerprint, ErrClaimFormat},
+		{"other fingerprint", "openpgp4fpr:" + testFingerprint[:39] + "0", ErrNoFingerprint},
+		{"empty", "", ErrNoFingerprint},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := matchClaim(tt.text, testFingerprint); err != tt.err {
+				t.Errorf("matchClaim(%q) = %v, want %v", tt.text, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestCheckClaim(t *testing.T) {
+	claim := "openpgp4fpr:" + testFingerprint
+
+	tests := []struct {
+		name  string
+		owner []string
+		rest  []string
+		err   error
+	}{
+		{"claim in owner", []string{"bio", claim}, nil, nil},
+		{"claim in owner and rest", []string{claim}, []string{claim}, nil},
+		{"bare fingerprint in owner", []string{testFingerprint}, nil, ErrClaimFormat},
+		{"claim only in rest", []string{"bio"}, []string{"comment " + claim}, ErrNotOwnerField},
+		{"bare fingerprint only in rest", nil, []string{testFingerprint}, ErrNotOwnerField},
+		{"no fingerprint", []string{"bio"}, []string{"comment"}, ErrNoFingerprint},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkClaim(testFingerprint, tt.owner, tt.rest...); err != tt.err {
+				t.Errorf("checkClaim(%q, %q) = %v, want %v", tt.owner, tt.rest, err, tt.err)
+			}
+		})
+	}
+}
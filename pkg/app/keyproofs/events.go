@@ -0,0 +1,121 @@
+package app_keyproofs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+	zlog "github.com/rs/zerolog/log"
+
+	"github.com/sour-is/keyproofs/pkg/opgp"
+	"github.com/sour-is/keyproofs/pkg/style"
+)
+
+// streamTimeout keeps event streams inside the server write timeout. Clients
+// reconnect and pick up from cache if checks are still running.
+var streamTimeout = 10 * time.Second
+
+type awaiter interface {
+	Await() <-chan struct{}
+	Result() interface{}
+	Err() error
+}
+
+type proofEvent struct {
+	URI   string `json:"uri"`
+	Proof *Proof `json:"proof"`
+}
+
+// getProofEvents streams proof status as server-sent events. It emits an entity event
+// once the key is read, a proof event as each check finishes, and complete once the
+// style and all proofs are done.
+func (app *keyproofApp) getProofEvents(w http.ResponseWriter, r *http.Request) {
+	log := zlog.Ctx(r.Context())
+
+	id := chi.URLParam(r, "id")
+	log.Debug().Str("events ", id).Send()
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeText(w, http.StatusInternalServerError, "streaming not supported")
+		return
+	}
+
+	if _, _, err := opgp.ParseID(id); err != nil {
+		writeText(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), streamTimeout)
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "retry: 1000\n\n")
+	flusher.Flush()
+
+	e, err := app.resolve(ctx, id)
+	if err != nil {
+		writeEvent(w, "failed", err.Error())
+		flusher.Flush()
+		return
+	}
+	if e == nil {
+		return
+	}
+	writeEvent(w, "entity", e.Fingerprint)
+	flusher.Flush()
+
+	type pending struct {
+		uri  string
+		task awaiter
+	}
+
+	tasks := make([]pending, 0, len(e.Proofs)+1)
+	tasks = append(tasks, pending{"", app.tasker.Run(style.Key(e.Primary.Address), styleTask)})
+	for _, uri := range e.Proofs {
+		tasks = append(tasks, pending{uri, app.tasker.Run(ProofKey(uri), proofTask(e.Fingerprint, e.Notations[uri]))})
+	}
+
+	done := make(chan pending)
+	for _, p := range tasks {
+		go func(p pending) {
+			select {
+			case <-p.task.Await():
+				select {
+				case done <- p:
+				case <-ctx.Done():
+				}
+			case <-ctx.Done():
+			}
+		}(p)
+	}
+
+	for range tasks {
+		select {
+		case p := <-done:
+			if p.task.Err() != nil {
+				continue
+			}
+			if proof, ok := p.task.Result().(*Proof); ok {
+				writeEvent(w, "proof", proofEvent{URI: p.uri, Proof: proof})
+				flusher.Flush()
+			}
+
+		case <-ctx.Done():
+			return
+		}
+	}
+
+	writeEvent(w, "complete", e.Fingerprint)
+	flusher.Flush()
+}
+
+func writeEvent(w http.ResponseWriter, event string, data interface{}) {
+	b, _ := json.Marshal(data)
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, b)
+}
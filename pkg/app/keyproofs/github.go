@@ -0,0 +1,140 @@
+package app_keyproofs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+var ErrOwnerMismatch = errors.New("owner does not match proof user")
+var ErrRateLimited = errors.New("rate limited by remote")
+
+type githubResolve struct {
+	proof   Proof
+	user    string
+	id      string
+	headers map[string]string
+}
+
+// githubLimit is shared by all gist checks so requests stop once the limit is used up.
+var githubLimit = &rateLimit{}
+
+func (r *githubResolve) Resolve(ctx context.Context) error {
+	log := log.Ctx(ctx)
+
+	if reset, ok := githubLimit.Limited(); ok {
+		return r.proof.setStatus(rateLimited(reset))
+	}
+
+	uri := fmt.Sprintf("https://api.github.com/gists/%s", r.id)
+	log.Info().Str("URI", uri).Msg("githubResolve")
+
+	req, err := http.NewRequestWithContext(ctx, "GET", uri, nil)
+	if err != nil {
+		return r.proof.setStatus(err)
+	}
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	req.Header.Set("User-Agent", "keyproofs/0.1.0")
+	for k, v := range r.headers {
+		req.Header.Set(k, v)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return r.proof.setStatus(err)
+	}
+	defer res.Body.Close()
+
+	githubLimit.Update(res.Header)
+
+	switch {
+	case res.StatusCode == http.StatusTooManyRequests,
+		res.StatusCode == http.StatusForbidden && res.Header.Get("X-RateLimit-Remaining") == "0":
+		reset, _ := githubLimit.Limited()
+		return r.proof.setStatus(rateLimited(reset))
+	case res.StatusCode != http.StatusOK:
+		return r.proof.setStatus(fmt.Errorf("bad response from remote: %s", res.Status))
+	}
+
+	gist := struct {
+		Owner struct {
+			Login string `json:"login"`
+		} `json:"owner"`
+		Files map[string]struct {
+			Content   string `json:"content"`
+			Truncated bool   `json:"truncated"`
+			RawURL    string `json:"raw_url"`
+		} `json:"files"`
+	}{}
+	if err = json.NewDecoder(res.Body).Decode(&gist); err != nil {
+		return r.proof.setStatus(err)
+	}
+
+	if !strings.EqualFold(gist.Owner.Login, r.user) {
+		return r.proof.setStatus(ErrOwnerMismatch)
+	}
+
+	var fields []string
+	for _, file := range gist.Files {
+		if file.Truncated && file.RawURL != "" {
+			body, err := getHTTP(ctx, file.RawURL, nil)
+			if err != nil {
+				return r.proof.setStatus(err)
+			}
+			file.Content = string(body)
+		}
+		fields = append(fields, file.Content)
+	}
+
+	return r.proof.setStatus(checkClaim(r.proof.Fingerprint, fields))
+}
+func (r *githubResolve) Proof() *Proof {
+	return &r.proof
+}
+
+func rateLimited(reset time.Time) error {
+	if reset.IsZero() {
+		return ErrRateLimited
+	}
+
+	return fmt.Errorf("%w until %s", ErrRateLimited, reset.Format(time.RFC3339))
+}
+
+// rateLimit tracks the X-RateLimit headers of an api.
+type rateLimit struct {
+	mu    sync.Mutex
+	reset time.Time
+}
+
+// Limited reports if the limit is used up and when it resets.
+func (l *rateLimit) Limited() (time.Time, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.reset, time.Now().Before(l.reset)
+}
+
+func (l *rateLimit) Update(hdr http.Header) {
+	reset := time.Now().Add(time.Minute)
+	if v, err := strconv.ParseInt(hdr.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		reset = time.Unix(v, 0)
+	}
+	if v, err := strconv.Atoi(hdr.Get("Retry-After")); err == nil {
+		reset = time.Now().Add(time.Duration(v) * time.Second)
+	} else if hdr.Get("X-RateLimit-Remaining") != "0" {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.reset = reset
+}
@@ -0,0 +1,115 @@
+package app_keyproofs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/sour-is/keyproofs/pkg/config"
+)
+
+// gitlabMaxPages bounds the project listing fallback.
+var gitlabMaxPages = 20
+
+type gitlabResolve struct {
+	proof Proof
+	user  string
+}
+
+type gitlabProject struct {
+	Path        string `json:"path"`
+	Description string `json:"description"`
+	Owner       *struct {
+		ID int `json:"id"`
+	} `json:"owner"`
+	Namespace struct {
+		Kind string `json:"kind"`
+		Path string `json:"path"`
+	} `json:"namespace"`
+}
+
+func (r *gitlabResolve) Resolve(ctx context.Context) error {
+	host := r.proof.URI.Host
+	api := fmt.Sprintf("https://%s/api/v4", host)
+	hdr := gitlabHeaders(ctx, host)
+
+	users := []struct {
+		ID       int    `json:"id"`
+		Username string `json:"username"`
+	}{}
+	if err := getJSON(ctx, fmt.Sprintf("%s/users?username=%s", api, url.QueryEscape(r.user)), hdr, &users); err != nil {
+		return r.proof.setStatus(err)
+	}
+	if len(users) == 0 {
+		return r.proof.setStatus(fmt.Errorf("%w: user %s not found", ErrOwnerMismatch, r.user))
+	}
+	u := users[0]
+
+	project := &gitlabProject{}
+	path := url.PathEscape(u.Username + "/gitlab_proof")
+	err := getJSON(ctx, fmt.Sprintf("%s/projects/%s", api, path), hdr, project)
+	if se := (*statusError)(nil); errors.As(err, &se) && se.Code == http.StatusNotFound {
+		project, err = r.findProject(ctx, api, hdr, u.ID)
+	}
+	if err != nil {
+		return r.proof.setStatus(err)
+	}
+	if project == nil {
+		return r.proof.setStatus(ErrNoFingerprint)
+	}
+
+	if project.Owner != nil {
+		if project.Owner.ID != u.ID {
+			return r.proof.setStatus(ErrOwnerMismatch)
+		}
+	} else if project.Namespace.Kind != "user" || !strings.EqualFold(project.Namespace.Path, u.Username) {
+		return r.proof.setStatus(ErrOwnerMismatch)
+	}
+
+	return r.proof.setStatus(checkClaim(r.proof.Fingerprint, []string{project.Description}))
+}
+func (r *gitlabResolve) Proof() *Proof {
+	return &r.proof
+}
+
+// findProject pages through the projects of a user looking for gitlab_proof.
+func (r *gitlabResolve) findProject(ctx context.Context, api string, hdr map[string]string, id int) (*gitlabProject, error) {
+	page := "1"
+	for i := 0; i < gitlabMaxPages && page != ""; i++ {
+		uri := fmt.Sprintf("%s/users/%d/projects?per_page=100&page=%s", api, id, page)
+		body, resHdr, err := getHTTPHeader(ctx, uri, hdr)
+		if err != nil {
+			return nil, err
+		}
+
+		var projects []*gitlabProject
+		if err = json.Unmarshal(body, &projects); err != nil {
+			return nil, err
+		}
+		for _, p := range projects {
+			if p.Path == "gitlab_proof" {
+				return p, nil
+			}
+		}
+
+		page = resHdr.Get("X-Next-Page")
+	}
+
+	return nil, nil
+}
+
+// gitlabHeaders returns the access token configured for host. Tokens are read
+// from the gitlab.tokens config as space separated host=token pairs.
+func gitlabHeaders(ctx context.Context, host string) map[string]string {
+	for _, pair := range strings.Fields(config.FromContext(ctx).GetString("gitlab.tokens")) {
+		if sp := strings.SplitN(pair, "=", 2); len(sp) == 2 && strings.EqualFold(sp[0], host) {
+			return map[string]string{"PRIVATE-TOKEN": sp[1]}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package app_keyproofs
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/sour-is/keyproofs/pkg/config"
+)
+
+type hackernewsResolve struct {
+	proof Proof
+	user  string
+	item  string
+}
+
+func (r *hackernewsResolve) Resolve(ctx context.Context) error {
+	api := strings.TrimSuffix(config.FromContext(ctx).GetString("hackernews.api-url"), "/")
+	if api == "" {
+		api = "https://hacker-news.firebaseio.com"
+	}
+
+	// An item proof points at the user through its author. The item text is
+	// only used to explain a claim missing from the user about.
+	user, rest := r.user, []string(nil)
+	if r.item != "" {
+		item := struct {
+			By   string `json:"by"`
+			Text string `json:"text"`
+		}{}
+		if err := getJSON(ctx, fmt.Sprintf("%s/v0/item/%s.json", api, url.PathEscape(r.item)), nil, &item); err != nil {
+			return r.proof.setStatus(err)
+		}
+		if item.By == "" {
+			return r.proof.setStatus(fmt.Errorf("%w: item %s not found", ErrOwnerMismatch, r.item))
+		}
+		user, rest = item.By, []string{htmlText(item.Text)}
+	}
+
+	about := struct {
+		ID    string `json:"id"`
+		About string `json:"about"`
+	}{}
+	if err := getJSON(ctx, fmt.Sprintf("%s/v0/user/%s.json", api, url.PathEscape(user)), nil, &about); err != nil {
+		return r.proof.setStatus(err)
+	}
+	if about.ID == "" {
+		return r.proof.setStatus(fmt.Errorf("%w: user %s not found", ErrOwnerMismatch, user))
+	}
+	r.setUser(about.ID)
+
+	return r.proof.setStatus(checkClaim(r.proof.Fingerprint, []string{htmlText(about.About)}, rest...))
+}
+func (r *hackernewsResolve) Proof() *Proof {
+	return &r.proof
+}
+
+func (r *hackernewsResolve) setUser(user string) {
+	r.proof.Name = user
+	r.proof.Link = "https://news.ycombinator.com/user?id=" + url.QueryEscape(user)
+}
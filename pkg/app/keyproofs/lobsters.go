@@ -0,0 +1,47 @@
+package app_keyproofs
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+type lobstersResolve struct {
+	proof Proof
+	user  string
+}
+
+func (r *lobstersResolve) Resolve(ctx context.Context) error {
+	user := struct {
+		Username string `json:"username"`
+		About    string `json:"about"`
+	}{}
+
+	uri := fmt.Sprintf("https://lobste.rs/~%s.json", url.PathEscape(r.user))
+	if err := getJSON(ctx, uri, nil, &user); err != nil {
+		return r.proof.setStatus(err)
+	}
+	if !strings.EqualFold(user.Username, r.user) {
+		return r.proof.setStatus(ErrOwnerMismatch)
+	}
+	r.proof.Name = user.Username
+
+	return r.proof.setStatus(checkClaim(r.proof.Fingerprint, []string{user.About}))
+}
+func (r *lobstersResolve) Proof() *Proof {
+	return &r.proof
+}
+
+// lobstersUser reads the user from either the /u/user or /~user url form.
+func lobstersUser(p string) string {
+	p = strings.Trim(p, "/")
+	if strings.HasPrefix(p, "~") {
+		return strings.TrimSuffix(strings.TrimPrefix(p, "~"), ".json")
+	}
+	if sp := strings.Split(p, "/"); len(sp) == 2 && sp[0] == "u" {
+		return strings.TrimSuffix(sp[1], ".json")
+	}
+
+	return ""
+}
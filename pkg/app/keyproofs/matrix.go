@@ -0,0 +1,63 @@
+package app_keyproofs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/sour-is/keyproofs/pkg/config"
+)
+
+var ErrSenderMismatch = errors.New("sender does not match proof user")
+var ErrMatrixNotConfigured = errors.New("matrix homeserver not configured")
+
+type matrixResolve struct {
+	proof Proof
+	user  string
+	room  string
+	event string
+}
+
+func (r *matrixResolve) Resolve(ctx context.Context) error {
+	cfg := config.FromContext(ctx)
+
+	homeserver := strings.TrimSuffix(cfg.GetString("matrix.homeserver"), "/")
+	if homeserver == "" {
+		return r.proof.setStatus(ErrMatrixNotConfigured)
+	}
+
+	var hdr map[string]string
+	if token := cfg.GetString("matrix.access-token"); token != "" {
+		hdr = map[string]string{"Authorization": "Bearer " + token}
+	}
+
+	event := struct {
+		Sender  string `json:"sender"`
+		Type    string `json:"type"`
+		Content struct {
+			Body string `json:"body"`
+		} `json:"content"`
+		ErrCode string `json:"errcode"`
+		Error   string `json:"error"`
+	}{}
+
+	uri := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/event/%s",
+		homeserver, url.PathEscape(r.room), url.PathEscape(r.event))
+	if err := httpJSON(ctx, uri, hdr, &event); err != nil {
+		return r.proof.setStatus(err)
+	}
+	if event.ErrCode != "" {
+		return r.proof.setStatus(fmt.Errorf("matrix: %s %s", event.ErrCode, event.Error))
+	}
+
+	if event.Sender != r.user {
+		return r.proof.setStatus(ErrSenderMismatch)
+	}
+
+	return r.proof.setStatus(checkClaim(r.proof.Fingerprint, []string{event.Content.Body}))
+}
+func (r *matrixResolve) Proof() *Proof {
+	return &r.proof
+}
@@ -1,14 +1,12 @@
 package app_keyproofs
 
 import (
-	"bufio"
 	"bytes"
 	"context"
-	"encoding/base64"
 	"encoding/json"
-	"errors"
 	"fmt"
-	"math/rand"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
 	"strings"
@@ -18,15 +16,20 @@ import (
 )
 
 type Proof struct {
-	Fingerprint string
-	Icon        string
-	Service     string
-	Name        string
-	Verify      string
-	Link        string
-	Status      ProofStatus
-
-	URI *url.URL
+	Fingerprint string      `json:"fingerprint"`
+	Icon        string      `json:"icon"`
+	Service     string      `json:"service"`
+	Name        string      `json:"name"`
+	Nick        string      `json:"nick,omitempty"`
+	FullName    string      `json:"full_name,omitempty"`
+	Verify      string      `json:"verify"`
+	Link        string      `json:"link"`
+	Status      ProofStatus `json:"status"`
+	Reason      string      `json:"reason,omitempty"`
+	Backend     string      `json:"backend,omitempty"`
+	Notation    string      `json:"notation,omitempty"`
+
+	URI *url.URL `json:"-"`
 }
 type Proofs map[string]*Proof
 
@@ -43,6 +46,8 @@ const (
 	ProofError
 	ProofInvalid
 	ProofVerified
+	ProofDisabled
+	ProofRateLimited
 )
 
 func (p ProofStatus) String() string {
@@ -55,14 +60,22 @@ func (p ProofStatus) String() string {
 		return "Invalid"
 	case ProofVerified:
 		return "Verified"
+	case ProofDisabled:
+		return "Disabled"
+	case ProofRateLimited:
+		return "RateLimited"
 	default:
 		return ""
 	}
 }
 
+func (p ProofStatus) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
 func NewProof(ctx context.Context, uri, fingerprint string) ProofResolver {
 	log := log.Ctx(ctx)
-	baseURL := config.FromContext(ctx).GetString("base-url")
+	cfg := config.FromContext(ctx)
 
 	p := Proof{Verify: uri, Link: uri, Fingerprint: fingerprint}
 	defer log.Info().
@@ -85,168 +98,30 @@ func NewProof(ctx context.Context, uri, fingerprint string) ProofResolver {
 
 	p.Service = p.URI.Scheme
 
-	switch p.URI.Scheme {
-	case "dns":
-		p.Icon = "fas fa-globe"
-		p.Name = p.URI.Opaque
-		p.Link = fmt.Sprintf("https://%s", p.URI.Opaque)
-		p.Verify = fmt.Sprintf("%s/dns/%s", baseURL, p.URI.Opaque)
-		return &httpResolve{p, p.Verify, nil}
-
-	case "xmpp":
-		p.Icon = "fas fa-comments"
-		p.Name = p.URI.Opaque
-		p.Verify = fmt.Sprintf("%s/vcard/%s", baseURL, p.URI.Opaque)
-		return &httpResolve{p, p.Verify, nil}
-
-	case "https":
+	if p.URI.Scheme == "https" {
 		p.Icon = "fas fa-atlas"
 		p.Name = p.URI.Hostname()
 		p.Link = fmt.Sprintf("https://%s", p.URI.Hostname())
+	}
 
-		switch {
-		case strings.HasPrefix(p.URI.Host, "twitter.com"):
-			// TODO: Add api authenticated code path.
-			if sp := strings.SplitN(p.URI.Path, "/", 3); len(sp) > 1 {
-				p.Icon = "fab fa-twitter"
-				p.Service = "Twitter"
-				p.Name = sp[1]
-				p.Link = fmt.Sprintf("https://twitter.com/%s", p.Name)
-				p.Verify = fmt.Sprintf("https://twitter.com%s", p.URI.Path)
-				url := fmt.Sprintf("https://mobile.twitter.com%s", p.URI.Path)
-				return &httpResolve{p, url, nil}
-			}
-
-		case strings.HasPrefix(p.URI.Host, "news.ycombinator.com"):
-			p.Icon = "fab fa-hacker-news"
-			p.Service = "HackerNews"
-			p.Name = p.URI.Query().Get("id")
-			p.Link = uri
-			return &httpResolve{p, p.Verify, nil}
-
-		case strings.HasPrefix(p.URI.Host, "dev.to"):
-			if sp := strings.SplitN(p.URI.Path, "/", 3); len(sp) > 1 {
-				p.Icon = "fab fa-dev"
-				p.Service = "dev.to"
-				p.Name = sp[1]
-				p.Link = fmt.Sprintf("https://dev.to/%s", p.Name)
-				url := fmt.Sprintf("https://dev.to/api/articles/%s/%s", sp[1], sp[2])
-				return &httpResolve{p, url, nil}
-			}
-
-		case strings.HasPrefix(p.URI.Host, "reddit.com"), strings.HasPrefix(p.URI.Host, "www.reddit.com"):
-			var headers map[string]string
-
-			cfg := config.FromContext(ctx)
-			if apikey := cfg.GetString("reddit.api-key"); apikey != "" {
-				secret := cfg.GetString("reddit.secret")
-
-				headers = map[string]string{
-					"Authorization": fmt.Sprintf("basic %s",
-						base64.StdEncoding.EncodeToString([]byte(apikey+":"+secret))),
-					"User-Agent": "ipseity/0.1.0",
-				}
-			}
-
-			if sp := strings.SplitN(p.URI.Path, "/", 6); len(sp) > 5 {
-				p.Icon = "fab fa-reddit"
-				p.Service = "Reddit"
-				p.Name = sp[2]
-				p.Link = fmt.Sprintf("https://www.reddit.com/user/%s", p.Name)
-				url := fmt.Sprintf("https://api.reddit.com/user/%s/comments/%s/%s", sp[2], sp[4], sp[5])
-				return &httpResolve{p, url, headers}
-			}
-
-		case strings.HasPrefix(p.URI.Host, "gist.github.com"):
-			p.Icon = "fab fa-github"
-			p.Service = "GitHub"
-			if sp := strings.SplitN(p.URI.Path, "/", 3); len(sp) > 2 {
-				var headers map[string]string
-				if secret := config.FromContext(ctx).GetString("github.secret"); secret != "" {
-					headers = map[string]string{
-						"Authorization": fmt.Sprintf("bearer %s", secret),
-						"User-Agent":    "keyproofs/0.1.0",
-					}
-				}
-
-				p.Name = sp[1]
-				p.Link = fmt.Sprintf("https://github.com/%s", p.Name)
-				url := fmt.Sprintf("https://api.github.com/gists/%s", sp[2])
-				return &httpResolve{p, url, headers}
-			}
-
-		case strings.HasPrefix(p.URI.Host, "lobste.rs"):
-			if sp := strings.SplitN(p.URI.Path, "/", 3); len(sp) > 2 {
-				p.Icon = "fas fa-list-ul"
-				p.Service = "Lobsters"
-				p.Name = sp[2]
-				p.Link = uri
-				p.Verify += ".json"
-				return &httpResolve{p, p.Verify, nil}
-			}
-
-		case strings.HasSuffix(p.URI.Path, "/gitlab_proof"):
-			if sp := strings.SplitN(p.URI.Path, "/", 3); len(sp) > 1 {
-				p.Icon = "fab fa-gitlab"
-				p.Service = "GetLab"
-				p.Name = sp[1]
-				p.Link = fmt.Sprintf("https://%s/%s", p.URI.Host, p.Name)
-				p.Name = fmt.Sprintf("%s@%s", p.Name, p.URI.Host)
-				return &gitlabResolve{p}
-			}
-
-		case strings.HasSuffix(p.URI.Path, "/gitea_proof"):
-			if sp := strings.SplitN(p.URI.Path, "/", 3); len(sp) > 2 {
-				p.Icon = "fas fa-mug-hot"
-				p.Service = "Gitea"
-				p.Name = sp[1]
-				p.Link = fmt.Sprintf("https://%s/%s", p.URI.Host, p.Name)
-				p.Name = fmt.Sprintf("%s@%s", p.Name, p.URI.Host)
-				url := fmt.Sprintf("https://%s/api/v1/repos/%s/gitea_proof", p.URI.Host, sp[1])
-				return &httpResolve{p, url, nil}
-			}
-
-		case strings.Contains(p.URI.Path, "/conv/"):
-			if sp := strings.SplitN(p.URI.Path, "/", 3); len(sp) == 3 {
-				p.Icon = "fas fa-comment-alt"
-				p.Service = "Twtxt"
-				p.Name = fmt.Sprintf("...@%s", p.URI.Host)
-				p.Link = fmt.Sprintf("https://%s", p.URI.Host)
-
-				url := fmt.Sprintf("https://%s/api/v1/conv", p.URI.Host)
-				return &twtxtResolve{p, url, sp[2], nil}
-			}
-
-		case strings.Contains(p.URI.Path, "/twt/"):
-			if sp := strings.SplitN(p.URI.Path, "/", 3); len(sp) == 3 {
-				p.Icon = "fas fa-comment-alt"
-				p.Service = "Twtxt"
-				p.Name = fmt.Sprintf("...@%s", p.URI.Host)
-				p.Link = fmt.Sprintf("https://%s", p.URI.Host)
-
-				url := fmt.Sprintf("https://%s/twt/%v", p.URI.Host, sp[2])
-				return &httpResolve{p, url, nil}
-			}
-
-		default:
-			if sp := strings.SplitN(p.URI.Path, "/", 3); len(sp) > 1 {
-				p.Icon = "fas fa-project-diagram"
-				p.Service = "Fediverse"
-				if len(sp) > 2 && (sp[1] == "u" || sp[1] == "user" || sp[1] == "users") {
-					p.Name = sp[2]
-				} else {
-					p.Name = sp[1]
-				}
-				p.Name = fmt.Sprintf("%s@%s", p.Name, p.URI.Host)
-				p.Link = uri
-				return &httpResolve{p, p.Verify, nil}
-			}
-		}
-
-	default:
+	svc := services.Match(p.URI)
+	if svc == nil {
 		p.Icon = "exclamation-triangle"
 		p.Service = "unknown"
 		p.Name = "nobody"
+
+		return &p
+	}
+
+	if isDisabled(svc.Name, strings.Fields(cfg.GetString("proofs.disabled"))) {
+		p.Service = svc.Name
+		p.Status = ProofDisabled
+
+		return &disabledResolve{p}
+	}
+
+	if r := svc.New(ctx, p); r != nil {
+		return r
 	}
 
 	return &p
@@ -261,65 +136,31 @@ type httpResolve struct {
 	proof   Proof
 	url     string
 	headers map[string]string
+	fields  fieldFn
 }
 
 func (p *httpResolve) Resolve(ctx context.Context) error {
-	err := checkHTTP(ctx, p.url, p.proof.Fingerprint, p.headers)
-	if err == ErrNoFingerprint {
-		p.proof.Status = ProofInvalid
-	} else if err != nil {
-		p.proof.Status = ProofError
-	} else {
-		p.proof.Status = ProofVerified
+	body, err := getHTTP(ctx, p.url, p.headers)
+	if err != nil {
+		return p.proof.setStatus(err)
 	}
-	return err
+
+	return p.proof.setStatus(checkClaim(p.proof.Fingerprint, p.fields(body), string(body)))
 }
 func (p *httpResolve) Proof() *Proof {
 	return &p.proof
 }
 
-type gitlabResolve struct {
+// disabledResolve keeps the disabled status of a proof without checking it.
+type disabledResolve struct {
 	proof Proof
 }
 
-func (r *gitlabResolve) Resolve(ctx context.Context) error {
-	uri := r.proof.URI
-	r.proof.Status = ProofInvalid
-
-	if sp := strings.SplitN(uri.Path, "/", 3); len(sp) > 1 {
-		user := []struct {
-			Id int `json:"id"`
-		}{}
-		if err := httpJSON(ctx, fmt.Sprintf("https://%s/api/v4/users?username=%s", uri.Host, sp[1]), nil, &user); err != nil {
-			return err
-		}
-		if len(user) == 0 {
-			return ErrNoFingerprint
-		}
-		u := user[0]
-		url := fmt.Sprintf("https://%s/api/v4/users/%d/projects", uri.Host, u.Id)
-		proofs := []struct {
-			Description string
-		}{}
-		if err := httpJSON(ctx, url, nil, &proofs); err != nil {
-			return err
-		}
-		if len(proofs) == 0 {
-			return ErrNoFingerprint
-		}
-		ck := fmt.Sprintf("[Verifying my OpenPGP key: openpgp4fpr:%s]", strings.ToLower(r.proof.Fingerprint))
-		for _, p := range proofs {
-			if strings.Contains(p.Description, ck) {
-				r.proof.Status = ProofVerified
-				return nil
-			}
-		}
-	}
-
-	return ErrNoFingerprint
+func (p *disabledResolve) Resolve(ctx context.Context) error {
+	return nil
 }
-func (r *gitlabResolve) Proof() *Proof {
-	return &r.proof
+func (p *disabledResolve) Proof() *Proof {
+	return &p.proof
 }
 
 func (p *Proof) Resolve(ctx context.Context) error {
@@ -329,56 +170,23 @@ func (p *Proof) Proof() *Proof {
 	return p
 }
 
-type twtxtResolve struct {
-	proof   Proof             `json:"-"`
-	url     string            `json:"-"`
-	Hash    string            `json:"hash"`
-	headers map[string]string `json:"-"`
-}
-
-func (t *twtxtResolve) Resolve(ctx context.Context) error {
-	t.proof.Status = ProofInvalid
+// maxBody limits how much of a remote document is read.
+const maxBody = 1 << 20
 
-	twt := struct {
-		Twts []struct {
-			Text  string `json:"text"`
-			Twter struct{ Nick string }
-		} `json:"twts"`
-	}{}
-
-	if err := postJSON(ctx, t.url, nil, t, &twt); err != nil {
-		return err
-	}
-	if len(twt.Twts) > 0 {
-		nick := twt.Twts[0].Twter.Nick
-		t.proof.Name = fmt.Sprintf("%s@%s", nick, t.proof.URI.Host)
-		t.proof.Link += "/user/" + nick
-
-		ck := fmt.Sprintf("[Verifying my OpenPGP key: openpgp4fpr:%s]", t.proof.Fingerprint)
-		if strings.Contains(twt.Twts[0].Text, ck) {
-			t.proof.Status = ProofVerified
-			return nil
-		}
-	}
-
-	return ErrNoFingerprint
-}
-func (t *twtxtResolve) Proof() *Proof {
-	return &t.proof
+func getHTTP(ctx context.Context, uri string, hdr map[string]string) ([]byte, error) {
+	body, _, err := getHTTPHeader(ctx, uri, hdr)
+	return body, err
 }
 
-func checkHTTP(ctx context.Context, uri, fingerprint string, hdr map[string]string) error {
+func getHTTPHeader(ctx context.Context, uri string, hdr map[string]string) ([]byte, http.Header, error) {
 	log := log.Ctx(ctx)
 
-	log.Info().
-		Str("URI", uri).
-		Str("fp", fingerprint).
-		Msg("Proof")
+	log.Info().Str("URI", uri).Msg("getHTTP")
 
 	req, err := http.NewRequestWithContext(ctx, "GET", uri, nil)
 	if err != nil {
 		log.Err(err)
-		return err
+		return nil, nil, err
 	}
 	req.Header.Set("Accept", "application/json")
 	for k, v := range hdr {
@@ -388,25 +196,27 @@ func checkHTTP(ctx context.Context, uri, fingerprint string, hdr map[string]stri
 	res, err := http.DefaultClient.Do(req)
 	if err != nil {
 		log.Err(err)
-		return err
+		return nil, nil, err
 	}
 	defer res.Body.Close()
 
-	ts := rand.Int63()
-	log.Info().Str("uri", uri).Int64("ts", ts).Msg("Reading data")
-	defer log.Info().Str("uri", uri).Int64("ts", ts).Msg("Read data")
-
-	scan := bufio.NewScanner(res.Body)
-	for scan.Scan() {
-		if strings.Contains(strings.ToUpper(scan.Text()), fingerprint) {
-			return nil
-		}
+	if res.StatusCode != http.StatusOK {
+		return nil, res.Header, &statusError{res.StatusCode, res.Status}
 	}
 
-	return ErrNoFingerprint
+	body, err := ioutil.ReadAll(io.LimitReader(res.Body, maxBody))
+	return body, res.Header, err
 }
 
-var ErrNoFingerprint = errors.New("fingerprint not found")
+// statusError is returned for unexpected responses from a remote.
+type statusError struct {
+	Code   int
+	Status string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("bad response from remote: %s", e.Status)
+}
 
 func httpJSON(ctx context.Context, uri string, hdr map[string]string, dst interface{}) error {
 	log := log.Ctx(ctx)
@@ -433,6 +243,16 @@ func httpJSON(ctx context.Context, uri string, hdr map[string]string, dst interf
 	return json.NewDecoder(res.Body).Decode(dst)
 }
 
+// getJSON is like httpJSON but fails on a non-200 response.
+func getJSON(ctx context.Context, uri string, hdr map[string]string, dst interface{}) error {
+	body, err := getHTTP(ctx, uri, hdr)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(body, dst)
+}
+
 func postJSON(ctx context.Context, uri string, hdr map[string]string, payload, dst interface{}) error {
 	log := log.Ctx(ctx)
 
@@ -0,0 +1,213 @@
+package app_keyproofs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/sour-is/keyproofs/pkg/config"
+)
+
+var redditUserAgent = "keyproofs/0.1.0"
+
+// RedditAuth provides app-only OAuth tokens for the reddit api using the
+// client credentials grant. Tokens are cached until shortly before they expire.
+type RedditAuth struct {
+	tokenURL string
+	apikey   string
+	secret   string
+	client   *http.Client
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+func NewRedditAuth(tokenURL, apikey, secret string, client *http.Client) *RedditAuth {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &RedditAuth{tokenURL: tokenURL, apikey: apikey, secret: secret, client: client}
+}
+
+// Token returns a bearer token, requesting a new one if needed.
+func (a *RedditAuth) Token(ctx context.Context) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.token != "" && time.Now().Before(a.expires) {
+		return a.token, nil
+	}
+
+	log.Ctx(ctx).Info().Str("URI", a.tokenURL).Msg("RedditAuth")
+
+	form := url.Values{"grant_type": {"client_credentials"}}
+	req, err := http.NewRequestWithContext(ctx, "POST", a.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.SetBasicAuth(a.apikey, a.secret)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("User-Agent", redditUserAgent)
+
+	res, err := a.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("reddit token: bad response: %s", res.Status)
+	}
+
+	tok := struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type"`
+		ExpiresIn   int    `json:"expires_in"`
+		Error       string `json:"error"`
+	}{}
+	if err = json.NewDecoder(res.Body).Decode(&tok); err != nil {
+		return "", err
+	}
+	if tok.Error != "" || tok.AccessToken == "" {
+		return "", fmt.Errorf("reddit token: %s", tok.Error)
+	}
+
+	// Renew a minute early, or halfway through short lived tokens.
+	lifetime := time.Duration(tok.ExpiresIn) * time.Second
+	early := time.Minute
+	if early > lifetime/2 {
+		early = lifetime / 2
+	}
+
+	a.token = tok.AccessToken
+	a.expires = time.Now().Add(lifetime - early)
+
+	return a.token, nil
+}
+
+// Invalidate drops the cached token so the next call requests a new one.
+func (a *RedditAuth) Invalidate() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.token = ""
+}
+
+var redditAuth struct {
+	sync.Mutex
+	*RedditAuth
+}
+
+// getRedditAuth returns the token provider shared by all reddit proofs. It is
+// replaced if the configured credentials change.
+func getRedditAuth(ctx context.Context) *RedditAuth {
+	cfg := config.FromContext(ctx)
+
+	apikey := cfg.GetString("reddit.api-key")
+	if apikey == "" {
+		return nil
+	}
+	secret := cfg.GetString("reddit.secret")
+	tokenURL := cfg.GetString("reddit.token-url")
+
+	redditAuth.Lock()
+	defer redditAuth.Unlock()
+
+	if a := redditAuth.RedditAuth; a == nil || a.apikey != apikey || a.secret != secret || a.tokenURL != tokenURL {
+		redditAuth.RedditAuth = NewRedditAuth(tokenURL, apikey, secret, nil)
+	}
+
+	return redditAuth.RedditAuth
+}
+
+type redditResolve struct {
+	proof Proof
+	user  string
+	path  string
+}
+
+func (r *redditResolve) Resolve(ctx context.Context) error {
+	cfg := config.FromContext(ctx)
+	auth := getRedditAuth(ctx)
+
+	apiURL := cfg.GetString("reddit.api-url")
+	if apiURL == "" {
+		apiURL = "https://api.reddit.com"
+		if auth != nil {
+			apiURL = "https://oauth.reddit.com"
+		}
+	}
+	uri := strings.TrimSuffix(apiURL, "/") + r.path
+
+	body, err := r.get(ctx, auth, uri)
+	if se := (*statusError)(nil); auth != nil && errors.As(err, &se) && se.Code == http.StatusUnauthorized {
+		auth.Invalidate()
+		body, err = r.get(ctx, auth, uri)
+	}
+	if err != nil {
+		return r.proof.setStatus(err)
+	}
+
+	type listing struct {
+		Data struct {
+			Children []struct {
+				Data struct {
+					Author   string `json:"author"`
+					Selftext string `json:"selftext"`
+					Body     string `json:"body"`
+				} `json:"data"`
+			} `json:"children"`
+		} `json:"data"`
+	}
+
+	var lis []listing
+	if body = bytes.TrimSpace(body); len(body) > 0 && body[0] == '{' {
+		lis = make([]listing, 1)
+		err = json.Unmarshal(body, &lis[0])
+	} else {
+		err = json.Unmarshal(body, &lis)
+	}
+	if err != nil {
+		return r.proof.setStatus(err)
+	}
+
+	var owner, rest []string
+	for _, l := range lis {
+		for _, c := range l.Data.Children {
+			if strings.EqualFold(c.Data.Author, r.user) {
+				owner = append(owner, c.Data.Selftext, c.Data.Body)
+			} else {
+				rest = append(rest, c.Data.Selftext, c.Data.Body)
+			}
+		}
+	}
+
+	return r.proof.setStatus(checkClaim(r.proof.Fingerprint, owner, rest...))
+}
+func (r *redditResolve) Proof() *Proof {
+	return &r.proof
+}
+
+func (r *redditResolve) get(ctx context.Context, auth *RedditAuth, uri string) ([]byte, error) {
+	hdr := map[string]string{"User-Agent": redditUserAgent}
+	if auth != nil {
+		token, err := auth.Token(ctx)
+		if err != nil {
+			return nil, err
+		}
+		hdr["Authorization"] = "bearer " + token
+	}
+
+	return getHTTP(ctx, uri, hdr)
+}
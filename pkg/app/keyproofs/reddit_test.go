@@ -0,0 +1,130 @@
+package app_keyproofs
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/sour-is/keyproofs/pkg/config"
+)
+
+// fakeRedditToken issues numbered tokens with the client credentials grant.
+type fakeRedditToken struct {
+	mu        sync.Mutex
+	issued    int
+	expiresIn int
+}
+
+func (f *fakeRedditToken) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, pass, ok := r.BasicAuth()
+	if !ok || user != "apikey" || pass != "secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "client_credentials" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	f.mu.Lock()
+	f.issued++
+	n := f.issued
+	f.mu.Unlock()
+
+	fmt.Fprintf(w, `{"access_token":"token%d","token_type":"bearer","expires_in":%d}`, n, f.expiresIn)
+}
+
+func (f *fakeRedditToken) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.issued
+}
+
+func TestRedditAuthToken(t *testing.T) {
+	fake := &fakeRedditToken{expiresIn: 3600}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	ctx := context.Background()
+	auth := NewRedditAuth(srv.URL, "apikey", "secret", srv.Client())
+
+	for i := 0; i < 3; i++ {
+		token, err := auth.Token(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token != "token1" {
+			t.Errorf("token = %q, want the cached token1", token)
+		}
+	}
+	if n := fake.count(); n != 1 {
+		t.Errorf("issued %d tokens, want 1", n)
+	}
+
+	auth.Invalidate()
+	if token, err := auth.Token(ctx); err != nil || token != "token2" {
+		t.Errorf("after invalidate token = %q, %v, want token2", token, err)
+	}
+
+	bad := NewRedditAuth(srv.URL, "apikey", "wrong", srv.Client())
+	if _, err := bad.Token(ctx); err == nil {
+		t.Error("expected an error for bad credentials")
+	}
+}
+
+func TestRedditAuthShortLived(t *testing.T) {
+	fake := &fakeRedditToken{expiresIn: 30}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	ctx := context.Background()
+	auth := NewRedditAuth(srv.URL, "apikey", "secret", srv.Client())
+
+	for i := 0; i < 3; i++ {
+		if _, err := auth.Token(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := fake.count(); n != 1 {
+		t.Errorf("issued %d tokens, want 1", n)
+	}
+}
+
+func TestRedditResolveRetry(t *testing.T) {
+	fake := &fakeRedditToken{expiresIn: 3600}
+	tokenSrv := httptest.NewServer(fake)
+	defer tokenSrv.Close()
+
+	// The first token is treated as revoked.
+	apiSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "bearer token2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprintf(w, `[{"data":{"children":[{"data":{"author":"alice","selftext":"openpgp4fpr:%s"}}]}}]`, testFingerprint)
+	}))
+	defer apiSrv.Close()
+
+	cfg := config.New()
+	cfg.Set("reddit.api-key", "apikey")
+	cfg.Set("reddit.secret", "secret")
+	cfg.Set("reddit.token-url", tokenSrv.URL)
+	cfg.Set("reddit.api-url", apiSrv.URL)
+	ctx := cfg.Apply(context.Background())
+
+	uri, _ := url.Parse("https://www.reddit.com/user/alice/comments/abc/keyproof")
+	r := &redditResolve{Proof{URI: uri, Fingerprint: testFingerprint}, "alice", "/user/alice/comments/abc/keyproof"}
+	if err := r.Resolve(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if r.Proof().Status != ProofVerified {
+		t.Errorf("status = %v, want verified", r.Proof().Status)
+	}
+	if n := fake.count(); n != 2 {
+		t.Errorf("issued %d tokens, want 2", n)
+	}
+}
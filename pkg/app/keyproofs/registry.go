@@ -0,0 +1,117 @@
+package app_keyproofs
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ProofService describes how to recognise and resolve a proof for a single service.
+type ProofService struct {
+	// Name used to enable or disable the service from config.
+	Name string
+	// Priority orders matching. Lower values are matched first.
+	Priority int
+	// Match reports if the proof URI belongs to the service.
+	Match func(*url.URL) bool
+	// New builds the resolver for a matched proof. It may return nil if the
+	// URI is not in a form the service understands.
+	New func(context.Context, Proof) ProofResolver
+}
+
+type registry struct {
+	mu       sync.RWMutex
+	services []*ProofService
+}
+
+var services = &registry{}
+
+// Register adds a proof service to the default registry. Services with the
+// same name are replaced.
+func Register(svc ProofService) {
+	services.Register(svc)
+}
+
+func (r *registry) Register(svc ProofService) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, s := range r.services {
+		if s.Name == svc.Name {
+			r.services = append(r.services[:i], r.services[i+1:]...)
+			break
+		}
+	}
+
+	r.services = append(r.services, &svc)
+	sort.SliceStable(r.services, func(i, j int) bool {
+		return r.services[i].Priority < r.services[j].Priority
+	})
+}
+
+// Match returns the first service that matches the uri.
+func (r *registry) Match(uri *url.URL) *ProofService {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, svc := range r.services {
+		if svc.Match(uri) {
+			return svc
+		}
+	}
+
+	return nil
+}
+
+// Services lists the registered service names in match order.
+func (r *registry) Services() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	lis := make([]string, len(r.services))
+	for i, svc := range r.services {
+		lis[i] = svc.Name
+	}
+
+	return lis
+}
+
+func isDisabled(name string, disabled []string) bool {
+	for _, d := range disabled {
+		if strings.EqualFold(d, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchScheme(scheme string) func(*url.URL) bool {
+	return func(uri *url.URL) bool {
+		return uri.Scheme == scheme
+	}
+}
+
+// matchHost matches https uris on one of the hosts exactly. Prefixes would let
+// hosts like lobste.rs.example.com claim the service.
+func matchHost(hosts ...string) func(*url.URL) bool {
+	return func(uri *url.URL) bool {
+		if uri.Scheme != "https" {
+			return false
+		}
+		for _, host := range hosts {
+			if strings.EqualFold(uri.Hostname(), host) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func matchPath(fn func(path, s string) bool, s string) func(*url.URL) bool {
+	return func(uri *url.URL) bool {
+		return uri.Scheme == "https" && fn(uri.Path, s)
+	}
+}
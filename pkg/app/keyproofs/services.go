@@ -0,0 +1,286 @@
+package app_keyproofs
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/sour-is/keyproofs/pkg/config"
+)
+
+func init() {
+	Register(ProofService{
+		Name:     "dns",
+		Priority: 10,
+		Match:    matchScheme("dns"),
+		New: func(ctx context.Context, p Proof) ProofResolver {
+			baseURL := config.FromContext(ctx).GetString("dns-url")
+
+			p.Icon = "fas fa-globe"
+			p.Name = p.URI.Opaque
+			p.Link = fmt.Sprintf("https://%s", p.URI.Opaque)
+			p.Verify = fmt.Sprintf("%s/dns/%s", baseURL, p.URI.Opaque)
+			return &httpResolve{p, p.Verify, nil, textLines}
+		},
+	})
+
+	Register(ProofService{
+		Name:     "xmpp",
+		Priority: 10,
+		Match:    matchScheme("xmpp"),
+		New: func(ctx context.Context, p Proof) ProofResolver {
+			baseURL := config.FromContext(ctx).GetString("xmpp-url")
+
+			p.Icon = "fas fa-comments"
+			p.Name = p.URI.Opaque
+			p.Verify = fmt.Sprintf("%s/vcard/%s", baseURL, p.URI.Opaque)
+			return &xmppResolve{p, p.URI.Opaque, p.Verify}
+		},
+	})
+
+	Register(ProofService{
+		Name:     "matrix",
+		Priority: 10,
+		Match:    matchScheme("matrix"),
+		New: func(ctx context.Context, p Proof) ProofResolver {
+			q := p.URI.Query()
+			room, event := q.Get("org.keyoxide.r"), q.Get("org.keyoxide.e")
+			if sp := strings.SplitN(p.URI.Opaque, "/", 2); len(sp) == 2 && sp[0] == "u" && room != "" && event != "" {
+				// Keyoxide uris carry the user id without its @ sigil.
+				user := "@" + strings.TrimPrefix(sp[1], "@")
+				p.Icon = "fas fa-comment-dots"
+				p.Service = "Matrix"
+				p.Name = user
+				p.Link = fmt.Sprintf("https://matrix.to/#/%s", user)
+				p.Verify = fmt.Sprintf("https://matrix.to/#/%s/%s", room, event)
+				return &matrixResolve{p, user, room, event}
+			}
+			return nil
+		},
+	})
+
+	Register(ProofService{
+		Name:     "twitter",
+		Priority: 100,
+		Match:    matchHost("twitter.com"),
+		New: func(ctx context.Context, p Proof) ProofResolver {
+			if sp := strings.SplitN(p.URI.Path, "/", 4); len(sp) > 1 {
+				p.Icon = "fab fa-twitter"
+				p.Service = "Twitter"
+				p.Name = sp[1]
+				p.Link = fmt.Sprintf("https://twitter.com/%s", p.Name)
+				p.Verify = fmt.Sprintf("https://twitter.com%s", p.URI.Path)
+
+				var id string
+				if len(sp) > 3 && sp[2] == "status" {
+					id = strings.Trim(sp[3], "/")
+				}
+				return &twitterResolve{p, sp[1], id}
+			}
+			return nil
+		},
+	})
+
+	Register(ProofService{
+		Name:     "hackernews",
+		Priority: 110,
+		Match:    matchHost("news.ycombinator.com"),
+		New: func(ctx context.Context, p Proof) ProofResolver {
+			p.Icon = "fab fa-hacker-news"
+			p.Service = "HackerNews"
+			p.Link = p.Verify
+
+			id := p.URI.Query().Get("id")
+			if p.URI.Path == "/item" {
+				p.Name = "item " + id
+				return &hackernewsResolve{p, "", id}
+			}
+			p.Name = id
+			return &hackernewsResolve{p, id, ""}
+		},
+	})
+
+	Register(ProofService{
+		Name:     "devto",
+		Priority: 120,
+		Match:    matchHost("dev.to"),
+		New: func(ctx context.Context, p Proof) ProofResolver {
+			if sp := strings.SplitN(p.URI.Path, "/", 3); len(sp) > 2 {
+				p.Icon = "fab fa-dev"
+				p.Service = "dev.to"
+				p.Name = sp[1]
+				p.Link = fmt.Sprintf("https://dev.to/%s", p.Name)
+				url := fmt.Sprintf("https://dev.to/api/articles/%s/%s", sp[1], sp[2])
+				return &httpResolve{p, url, nil, jsonFields("body_markdown")}
+			}
+			return nil
+		},
+	})
+
+	Register(ProofService{
+		Name:     "reddit",
+		Priority: 130,
+		Match:    matchHost("reddit.com", "www.reddit.com"),
+		New: func(ctx context.Context, p Proof) ProofResolver {
+			if sp := strings.SplitN(p.URI.Path, "/", 6); len(sp) > 5 {
+				p.Icon = "fab fa-reddit"
+				p.Service = "Reddit"
+				p.Name = sp[2]
+				p.Link = fmt.Sprintf("https://www.reddit.com/user/%s", p.Name)
+				path := fmt.Sprintf("/user/%s/comments/%s/%s", sp[2], sp[4], sp[5])
+				return &redditResolve{p, sp[2], path}
+			}
+			return nil
+		},
+	})
+
+	Register(ProofService{
+		Name:     "github",
+		Priority: 140,
+		Match:    matchHost("gist.github.com"),
+		New: func(ctx context.Context, p Proof) ProofResolver {
+			p.Icon = "fab fa-github"
+			p.Service = "GitHub"
+			if sp := strings.SplitN(p.URI.Path, "/", 3); len(sp) > 2 {
+				var headers map[string]string
+				if secret := config.FromContext(ctx).GetString("github.secret"); secret != "" {
+					headers = map[string]string{
+						"Authorization": fmt.Sprintf("bearer %s", secret),
+						"User-Agent":    "keyproofs/0.1.0",
+					}
+				}
+
+				p.Name = sp[1]
+				p.Link = fmt.Sprintf("https://github.com/%s", p.Name)
+				return &githubResolve{p, sp[1], sp[2], headers}
+			}
+			return nil
+		},
+	})
+
+	Register(ProofService{
+		Name:     "lobsters",
+		Priority: 150,
+		Match:    matchHost("lobste.rs"),
+		New: func(ctx context.Context, p Proof) ProofResolver {
+			if user := lobstersUser(p.URI.Path); user != "" {
+				p.Icon = "fas fa-list-ul"
+				p.Service = "Lobsters"
+				p.Name = user
+				p.Link = fmt.Sprintf("https://lobste.rs/~%s", user)
+				return &lobstersResolve{p, user}
+			}
+			return nil
+		},
+	})
+
+	Register(ProofService{
+		Name:     "gitlab",
+		Priority: 200,
+		Match:    matchPath(strings.HasSuffix, "/gitlab_proof"),
+		New: func(ctx context.Context, p Proof) ProofResolver {
+			if sp := strings.SplitN(p.URI.Path, "/", 3); len(sp) > 1 {
+				p.Icon = "fab fa-gitlab"
+				p.Service = "GitLab"
+				p.Name = sp[1]
+				p.Link = fmt.Sprintf("https://%s/%s", p.URI.Host, p.Name)
+				p.Name = fmt.Sprintf("%s@%s", p.Name, p.URI.Host)
+				return &gitlabResolve{p, sp[1]}
+			}
+			return nil
+		},
+	})
+
+	Register(ProofService{
+		Name:     "gitea",
+		Priority: 210,
+		Match:    matchPath(strings.HasSuffix, "/gitea_proof"),
+		New: func(ctx context.Context, p Proof) ProofResolver {
+			if sp := strings.SplitN(p.URI.Path, "/", 3); len(sp) > 2 {
+				p.Icon = "fas fa-mug-hot"
+				p.Service = "Gitea"
+				p.Name = sp[1]
+				p.Link = fmt.Sprintf("https://%s/%s", p.URI.Host, p.Name)
+				p.Name = fmt.Sprintf("%s@%s", p.Name, p.URI.Host)
+				url := fmt.Sprintf("https://%s/api/v1/repos/%s/gitea_proof", p.URI.Host, sp[1])
+				return &httpResolve{p, url, nil, jsonFields("description")}
+			}
+			return nil
+		},
+	})
+
+	Register(ProofService{
+		Name:     "twtxt-conv",
+		Priority: 300,
+		Match:    matchPath(strings.Contains, "/conv/"),
+		New: func(ctx context.Context, p Proof) ProofResolver {
+			if sp := strings.SplitN(p.URI.Path, "/", 3); len(sp) == 3 {
+				p.Icon = "fas fa-comment-alt"
+				p.Service = "Twtxt"
+				p.Name = fmt.Sprintf("...@%s", p.URI.Host)
+				p.Link = fmt.Sprintf("https://%s", p.URI.Host)
+
+				url := fmt.Sprintf("https://%s/api/v1/conv", p.URI.Host)
+				return &twtxtResolve{p, url, sp[2], nil}
+			}
+			return nil
+		},
+	})
+
+	Register(ProofService{
+		Name:     "twtxt-twt",
+		Priority: 310,
+		Match:    matchPath(strings.Contains, "/twt/"),
+		New: func(ctx context.Context, p Proof) ProofResolver {
+			if sp := strings.SplitN(p.URI.Path, "/", 3); len(sp) == 3 {
+				p.Icon = "fas fa-comment-alt"
+				p.Service = "Twtxt"
+				p.Name = fmt.Sprintf("...@%s", p.URI.Host)
+				p.Link = fmt.Sprintf("https://%s", p.URI.Host)
+
+				url := fmt.Sprintf("https://%s/api/v1/conv", p.URI.Host)
+				return &twtxtResolve{p, url, sp[2], nil}
+			}
+			return nil
+		},
+	})
+
+	Register(ProofService{
+		Name:     "twtxt-feed",
+		Priority: 320,
+		Match:    matchPath(strings.HasSuffix, ".txt"),
+		New: func(ctx context.Context, p Proof) ProofResolver {
+			p.Icon = "fas fa-comment-alt"
+			p.Service = "Twtxt"
+			p.Name = fmt.Sprintf("%s@%s", twtxtNick(p.URI.Path), p.URI.Host)
+
+			u := *p.URI
+			u.Fragment = ""
+			p.Link = u.String()
+			return &twtxtFeedResolve{p, u.String(), p.URI.Fragment}
+		},
+	})
+
+	// Fediverse is the catch all for https proofs and must be matched last.
+	Register(ProofService{
+		Name:     "fediverse",
+		Priority: math.MaxInt32,
+		Match:    matchScheme("https"),
+		New: func(ctx context.Context, p Proof) ProofResolver {
+			if sp := strings.SplitN(p.URI.Path, "/", 3); len(sp) > 1 {
+				p.Icon = "fas fa-project-diagram"
+				p.Service = "Fediverse"
+				user := sp[1]
+				if len(sp) > 2 && (sp[1] == "u" || sp[1] == "user" || sp[1] == "users") {
+					user = sp[2]
+				}
+				user = strings.TrimPrefix(user, "@")
+				p.Name = fmt.Sprintf("%s@%s", user, p.URI.Host)
+				p.Link = p.Verify
+				return &fediverseResolve{p, user}
+			}
+			return nil
+		},
+	})
+}
@@ -1,16 +1,21 @@
 package app_keyproofs
 
 import (
+	"encoding/base64"
+	"html/template"
+
 	"github.com/sour-is/keyproofs/pkg/opgp/entity"
 	"github.com/sour-is/keyproofs/pkg/style"
 )
 
 type page struct {
-	AppName  string
-	AppBuild string
-	Entity   *entity.Entity
-	Style    *style.Style
-	Proofs   *Proofs
+	AppName    string
+	AppBuild   string
+	Entity     *entity.Entity
+	Style      *style.Style
+	Proofs     *Proofs
+	Identities []pageIdentity
+	Photo      template.URL
 
 	Markdown   string
 	HasProofs  bool
@@ -18,10 +23,48 @@ type page struct {
 	Err        error
 }
 
+// pageIdentity is a user id with the proofs it claims.
+type pageIdentity struct {
+	*entity.Identity
+	Proofs []pageProof
+}
+
+type pageProof struct {
+	Key string
+	*Proof
+}
+
+func newPageIdentities(e *entity.Entity, proofs Proofs) []pageIdentity {
+	var lis []pageIdentity
+	for _, id := range e.Identities {
+		if len(id.Proofs) == 0 {
+			continue
+		}
+
+		item := pageIdentity{Identity: id}
+		for _, uri := range id.Proofs {
+			item.Proofs = append(item.Proofs, pageProof{uri, proofs[uri]})
+		}
+		lis = append(lis, item)
+	}
+
+	return lis
+}
+
+// photoURL returns the first photo of the entity as a data url. Only photos
+// with a jpeg image header are read from the key.
+func photoURL(e *entity.Entity) template.URL {
+	if len(e.Photos) == 0 {
+		return ""
+	}
+
+	return template.URL("data:image/jpeg;base64," + base64.StdEncoding.EncodeToString(e.Photos[0]))
+}
+
 var pageTPL = `
 <html>
 <head>
-	{{if not .IsComplete}}<meta http-equiv="refresh" content="1">{{end}}
+	{{if not .IsComplete}}<noscript><meta http-equiv="refresh" content="1"></noscript>{{end}}
 
 	<link href="https://pagecdn.io/lib/bootstrap/4.5.1/css/bootstrap.min.css"       rel="stylesheet" crossorigin="anonymous" integrity="sha256-VoFZSlmyTXsegReQCNmbXrS4hBBUl/cexZvPmPWoJsY=" >
 	<link href="https://pagecdn.io/lib/font-awesome/5.14.0/css/fontawesome.min.css" rel="stylesheet" crossorigin="anonymous" integrity="sha256-7YMlwkILTJEm0TSengNDszUuNSeZu4KTN3z7XrhUQvc=" >
@@ -95,10 +138,46 @@ var pageTPL = `
 			</div>
 		</div>
 	</div>
+	{{if not .IsComplete}}{{template "events" .}}{{end}}
 </body>
 </html>
 `
 
+var eventsTPL = `
+{{define "events"}}
+<script>
+(function() {
+	var hasEntity = {{if .Entity}}true{{else}}false{{end}};
+	var status = {
+		"Error":    '<span class="text-warning"> <i class="fas fa-exclamation-triangle"></i> Error</span>',
+		"Invalid":  '<span class="text-danger"> <i class="far fa-times-circle"></i> Invalid</span>',
+		"Verified": '<span class="text-success"> <i class="far fa-check-square"></i> Verified</span>',
+		"Disabled": '<span class="text-muted"> <i class="fas fa-ban"></i> Disabled</span>',
+		"RateLimited": '<span class="text-warning"> <i class="fas fa-hourglass-half"></i> Rate Limited</span>'
+	};
+	var reload = function() { src.close(); window.location.reload(); };
+	var src = new EventSource(window.location.pathname + "/events");
+
+	src.addEventListener("entity", function() { if (!hasEntity) { reload(); } });
+	src.addEventListener("failed", reload);
+	src.addEventListener("complete", reload);
+	src.addEventListener("proof", function(ev) {
+		var e = JSON.parse(ev.data);
+		document.querySelectorAll("[data-proof]").forEach(function(el) {
+			if (el.getAttribute("data-proof") === e.uri && status[e.proof.status]) {
+				el.innerHTML = status[e.proof.status];
+				el.title = e.proof.reason || "";
+			}
+		});
+	});
+	src.onerror = function() {
+		if (src.readyState === EventSource.CLOSED) { setTimeout(reload, 1000); }
+	};
+})();
+</script>
+{{end}}
+`
+
 var homeTPL = `
 {{define "content"}}
 <div class="jumbotron heading">
@@ -119,8 +198,8 @@ var homeTPL = `
 				<input type="text"
 					   name="id"
 					   class="form-control"
-					   placeholder="Email or Fingerprint..."
-					   aria-label="Email or Fingerprint"
+					   placeholder="Email, Key ID or Fingerprint..."
+					   aria-label="Email, Key ID or Fingerprint"
 					   aria-describedby="button-addon" />
 				<div class="input-group-append">
 					<button class="btn btn-outline-secondary" type="submit" id="button-addon">GO</button>
@@ -150,7 +229,7 @@ var proofTPL = `
 		{{else}}
 			{{ with .Style }}
 				<div class="col-xs center-md">
-					<img src="{{.Avatar}}" class="img-thumbnail" alt="avatar" style="width:88px; height:88px">
+					<img src="{{with $.Photo}}{{.}}{{else}}{{.Avatar}}{{end}}" class="img-thumbnail" alt="avatar" style="width:88px; height:88px">
 				</div>
 			{{end}}
 
@@ -158,6 +237,7 @@ var proofTPL = `
 				<div class="col-md center-md">
 					<h1 class="display-8 fg-color-8">{{.Primary.Name}}</h1>
 					<p class="lead fg-color-11"><i class="fas fa-fingerprint"></i> {{.Fingerprint}}</p>
+					{{if .Verified}}<span class="badge badge-success"><i class="fas fa-check"></i> Self-signatures verified</span>{{else}}<span class="badge badge-danger"><i class="fas fa-times"></i> Self-signatures not verified</span>{{end}}
 				</div>
 				<div class="col-xs center-md">
 					<img src="/qr?s=-2&c=OPENPGP4FPR%3A{{.Fingerprint}}" class="img-thumbnail" alt="qrcode" style="width:88px; height:88px">
@@ -174,42 +254,67 @@ var proofTPL = `
 </div>
 
 <div class="container">
+	{{with .Entity}}{{range .Warnings}}
+	<div class="alert alert-danger" role="alert"><i class="fas fa-exclamation-triangle"></i> <b>{{.}}</b></div>
+	{{end}}{{end}}
 	<div class="row">
 		<div class="col-lg-4 col-md-12 col-sm-12 col-xs-12">
 		{{ with .Entity }}
 			<div class="card">
 				<div class="card-header">Contact</div>
 				<div class="list-group list-group-flush">
-					{{with .Primary}}<a href="mailto:{{.Address}}" class="list-group-item list-group-item-action"><i class="fas fa-envelope"></i> <b>{{.Name}} &lt;{{.Address}}&gt;</b> <span class="badge badge-secondary">Primary</span></a>{{end}}
+					{{if .HasEmail}}{{with .Primary}}<a href="mailto:{{.Address}}" class="list-group-item list-group-item-action"><i class="fas fa-envelope"></i> <b>{{.Name}} &lt;{{.Address}}&gt;</b> <span class="badge badge-secondary">Primary</span></a>{{end}}{{end}}
 					{{range .Emails}}<a href="mailto:{{.Address}}" class="list-group-item list-group-item-action"><i class="far fa-envelope"></i> {{.Name}} &lt;{{.Address}}&gt;</a>{{end}}
+					{{range .Identities}}{{if not .Address}}<div class="list-group-item"><i class="far fa-user"></i> {{.Name}}</div>{{end}}{{end}}
 				</div>
 			</div>
 			<br />
+			<div class="card">
+				<div class="card-header">Keys</div>
+				<ul class="list-group list-group-flush">
+					{{with .Key}}{{template "key" .}}{{end}}
+					{{range .Subkeys}}{{template "key" .}}{{end}}
+				</ul>
+			</div>
+			<br />
 		{{end}}
 
 		{{if .HasProofs}}
-		{{with .Proofs}}
+		{{range .Identities}}
 			<div class="card">
-				<div class="card-header">Proofs</div>
+				<div class="card-header">
+					Proofs for {{.Name}}
+					{{with .SelfSignature}}<div><small class="text-muted">Signed {{.CreationTime}}</small></div>{{end}}
+				</div>
 					<ul class="list-group list-group-flush">
-						{{range .}}
+						{{range .Proofs}}
 						<li class="list-group-item">
 							<div>
 								<a title="{{.Link}}" class="font-weight-bold" href="{{.Link}}">
-									<i title="{{.Service}}" class="{{.Icon}}"></i>
+									<i title="{{.Service}}{{with .Notation}} ({{.}}){{end}}" class="{{.Icon}}"></i>
 									{{.Name}}
 								</a>
+								{{with .FullName}}<small class="text-muted">{{.}}</small>{{end}}
+								{{with .Nick}}<small class="text-muted">({{.}})</small>{{end}}
 
+								<span data-proof="{{.Key}}">
 								{{if eq .Status 0}}
 									<a class="text-muted" href="{{.Verify}}"> <i class="fas fa-ellipsis-h"> Checking</i></a>
 								{{else if eq .Status 1}}
-									<a class="text-warning" href="{{.Verify}}"> <i class="fas fa-exclamation-triangle"></i> Error</a>
+									<a class="text-warning" href="{{.Verify}}" title="{{.Reason}}"> <i class="fas fa-exclamation-triangle"></i> Error</a>
 								{{else if eq .Status 2}}
-									<a class="text-danger" href="{{.Verify}}"> <i class="far fa-times-circle"></i> Invalid</a>
+									<a class="text-danger" href="{{.Verify}}" title="{{.Reason}}"> <i class="far fa-times-circle"></i> Invalid</a>
 								{{else if eq .Status 3}}
 									<a class="text-success" href="{{.Verify}}"> <i class="far fa-check-square"></i> Verified</a>
+								{{else if eq .Status 4}}
+									<span class="text-muted"> <i class="fas fa-ban"></i> Disabled</span>
+								{{else if eq .Status 5}}
+									<a class="text-warning" href="{{.Verify}}" title="{{.Reason}}"> <i class="fas fa-hourglass-half"></i> Rate Limited</a>
 								{{end}}
+								</span>
 							</div>
+							{{with .Reason}}<div><small class="text-muted">{{.}}</small></div>{{end}}
+							{{with .Backend}}<div><small class="text-muted">via {{.}}</small></div>{{end}}
 							<div>
 							{{if eq .Service "xmpp"}}
 								<br/>
@@ -219,8 +324,8 @@ var proofTPL = `
 						</li>
 						{{end}}
 					</ul>
-				</div>
 			</div>
+			<br/>
 		{{else}}
 			<div class="card">
 				<div class="card-header">Proofs</div>
@@ -229,21 +334,41 @@ var proofTPL = `
 			<br/>
 		{{end}}
 		{{end}}
+		</div>
+		{{with .Entity}}
 		<div class="col-lg-8 col-md-12 col-sm-12 col-xs-12">
 			<div class="card">
 				<div class="card-header">Public Key</div>
 				<div class="card-body scroll">
 					<pre><code>
-Last Updated {{.Entity.SelfSignature.CreationTime}}
-
-{{.Entity.ArmorText}}
+{{with .SelfSignature}}Last Updated {{.CreationTime}}
+{{end}}{{with .Source}}Source {{.}}
+{{end}}
+{{.ArmorText}}
 </code></pre>
 				</div>
 			</div>
 		</div>
+		{{end}}
 	</div>
 </div>
 {{end}}
+
+{{define "key"}}
+<li class="list-group-item{{if or .Revoked .Expired}} text-muted{{end}}">
+	<div>
+		<i class="fas fa-key"></i>
+		<b>{{.Algorithm}}{{with .Curve}} {{.}}{{else}}{{with .BitLength}} {{.}}{{end}}{{end}}</b>
+		<small>{{.KeyID}}</small>
+	</div>
+	<div>
+		{{range .Flags}}<span class="badge badge-secondary">{{.}}</span> {{end}}
+		{{if .Revoked}}<span class="badge badge-danger" title="{{.RevocationReason}}">revoked</span>{{end}}
+		{{if .Expired}}<span class="badge badge-warning">expired</span>{{end}}
+	</div>
+	<div><small class="text-muted">Created {{.Created.Format "2006-01-02"}}{{with .Expires}}, expires {{.Format "2006-01-02"}}{{end}}</small></div>
+</li>
+{{end}}
 `
 
 var homeMKDN = `
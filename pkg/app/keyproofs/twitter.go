@@ -0,0 +1,163 @@
+package app_keyproofs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/sour-is/keyproofs/pkg/config"
+)
+
+var ErrNoBackend = errors.New("no backend available")
+
+// twitterBackends is the default order backends are tried in.
+var twitterBackends = []string{"api", "nitter", "scrape"}
+
+// twitterResolve checks a tweet, or the profile bio if there is no tweet id.
+type twitterResolve struct {
+	proof Proof
+	user  string
+	id    string
+}
+
+// Resolve tries each configured backend in turn until one gives an answer. A
+// backend that fails is skipped but an invalid proof is final.
+func (r *twitterResolve) Resolve(ctx context.Context) error {
+	cfg := config.FromContext(ctx)
+
+	backends := strings.Fields(cfg.GetString("twitter.backends"))
+	if len(backends) == 0 {
+		backends = twitterBackends
+	}
+
+	// Only the backend that answered is recorded.
+	r.proof.Backend = ""
+	answered := func(err error, name string) bool {
+		if err == nil || isInvalid(err) {
+			r.proof.Backend = name
+			return true
+		}
+		return false
+	}
+
+	err := ErrNoBackend
+	for _, backend := range backends {
+		switch backend {
+		case "api":
+			token := cfg.GetString("twitter.bearer-token")
+			if token == "" {
+				continue
+			}
+			err = r.checkAPI(ctx, token)
+			answered(err, "api.twitter.com")
+
+		case "nitter":
+			for _, instance := range strings.Fields(cfg.GetString("twitter.nitter")) {
+				name := instance
+				if u, e := url.Parse(instance); e == nil && u.Host != "" {
+					name = u.Host
+				}
+				if err = r.checkNitter(ctx, instance); answered(err, name) {
+					break
+				}
+			}
+
+		case "scrape":
+			err = r.checkScrape(ctx)
+			answered(err, "mobile.twitter.com")
+
+		default:
+			continue
+		}
+
+		if r.proof.Backend != "" {
+			break
+		}
+	}
+
+	return r.proof.setStatus(err)
+}
+func (r *twitterResolve) Proof() *Proof {
+	return &r.proof
+}
+
+func (r *twitterResolve) checkAPI(ctx context.Context, token string) error {
+	hdr := map[string]string{"Authorization": "Bearer " + token}
+
+	if r.id == "" {
+		user := struct {
+			Data struct {
+				Username    string `json:"username"`
+				Description string `json:"description"`
+			} `json:"data"`
+		}{}
+
+		uri := fmt.Sprintf("https://api.twitter.com/2/users/by/username/%s?user.fields=description", url.PathEscape(r.user))
+		if err := getJSON(ctx, uri, hdr, &user); err != nil {
+			return err
+		}
+		if !strings.EqualFold(user.Data.Username, r.user) {
+			return ErrOwnerMismatch
+		}
+
+		return checkClaim(r.proof.Fingerprint, []string{user.Data.Description})
+	}
+
+	tweet := struct {
+		Data struct {
+			Text string `json:"text"`
+		} `json:"data"`
+		Includes struct {
+			Users []struct {
+				Username string `json:"username"`
+			} `json:"users"`
+		} `json:"includes"`
+	}{}
+
+	uri := fmt.Sprintf("https://api.twitter.com/2/tweets/%s?expansions=author_id&user.fields=username", url.PathEscape(r.id))
+	if err := getJSON(ctx, uri, hdr, &tweet); err != nil {
+		return err
+	}
+	if len(tweet.Includes.Users) == 0 || !strings.EqualFold(tweet.Includes.Users[0].Username, r.user) {
+		return ErrOwnerMismatch
+	}
+
+	return checkClaim(r.proof.Fingerprint, []string{tweet.Data.Text})
+}
+
+var nitterAuthor = regexp.MustCompile(`<meta\s+property="og:title"\s+content="[^"]*\(@([^)"]+)\)"`)
+
+func (r *twitterResolve) checkNitter(ctx context.Context, instance string) error {
+	uri := strings.TrimSuffix(instance, "/") + r.path()
+	body, err := getHTTP(ctx, uri, map[string]string{"Accept": "text/html"})
+	if err != nil {
+		return err
+	}
+
+	if m := nitterAuthor.FindSubmatch(body); m != nil && !strings.EqualFold(string(m[1]), r.user) {
+		return ErrOwnerMismatch
+	}
+
+	return checkClaim(r.proof.Fingerprint, htmlFields(ogDescription)(body))
+}
+
+func (r *twitterResolve) checkScrape(ctx context.Context) error {
+	uri := "https://mobile.twitter.com" + r.path()
+	body, err := getHTTP(ctx, uri, nil)
+	if err != nil {
+		return err
+	}
+
+	return checkClaim(r.proof.Fingerprint, htmlFields(ogDescription)(body))
+}
+
+func (r *twitterResolve) path() string {
+	if r.id == "" {
+		return "/" + url.PathEscape(r.user)
+	}
+
+	return fmt.Sprintf("/%s/status/%s", url.PathEscape(r.user), url.PathEscape(r.id))
+}
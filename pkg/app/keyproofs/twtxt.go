@@ -0,0 +1,159 @@
+package app_keyproofs
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base32"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+type twtxtResolve struct {
+	proof   Proof             `json:"-"`
+	url     string            `json:"-"`
+	Hash    string            `json:"hash"`
+	headers map[string]string `json:"-"`
+}
+
+func (t *twtxtResolve) Resolve(ctx context.Context) error {
+
+	twt := struct {
+		Twts []struct {
+			Hash  string `json:"hash"`
+			Text  string `json:"text"`
+			Twter struct{ Nick string }
+		} `json:"twts"`
+	}{}
+
+	if err := postJSON(ctx, t.url, nil, t, &twt); err != nil {
+		return t.proof.setStatus(err)
+	}
+	for _, item := range twt.Twts {
+		// The conversation includes replies so only check the twt with the proof hash.
+		if item.Hash != "" && item.Hash != t.Hash {
+			continue
+		}
+
+		nick := item.Twter.Nick
+		t.proof.Name = fmt.Sprintf("%s@%s", nick, t.proof.URI.Host)
+		t.proof.Link += "/user/" + nick
+
+		return t.proof.setStatus(checkClaim(t.proof.Fingerprint, []string{item.Text}))
+	}
+
+	return t.proof.setStatus(ErrNoFingerprint)
+}
+func (t *twtxtResolve) Proof() *Proof {
+	return &t.proof
+}
+
+// twtxtFeedResolve checks a plain twtxt.txt feed. If the proof url has a
+// fragment only the twt with that hash is checked.
+type twtxtFeedResolve struct {
+	proof Proof
+	url   string
+	hash  string
+}
+
+func (t *twtxtFeedResolve) Resolve(ctx context.Context) error {
+	body, err := getHTTP(ctx, t.url, map[string]string{"Accept": "text/plain"})
+	if err != nil {
+		return t.proof.setStatus(err)
+	}
+
+	feed := parseTwtxt(body)
+
+	feedURL := t.url
+	if feed.URL != "" {
+		feedURL = feed.URL
+	}
+	t.proof.Link = feedURL
+	if feed.Nick != "" {
+		t.proof.Name = fmt.Sprintf("%s@%s", feed.Nick, t.proof.URI.Host)
+	}
+
+	var fields []string
+	for _, twt := range feed.Twts {
+		if t.hash == "" || twt.Hash(feedURL) == t.hash {
+			fields = append(fields, twt.Text)
+		}
+	}
+
+	return t.proof.setStatus(checkClaim(t.proof.Fingerprint, fields))
+}
+func (t *twtxtFeedResolve) Proof() *Proof {
+	return &t.proof
+}
+
+type twtxtFeed struct {
+	Nick string
+	URL  string
+	Twts []twtxtTwt
+}
+
+type twtxtTwt struct {
+	Created time.Time
+	Text    string
+}
+
+// Hash returns the twt hash as used by yarn.social pods.
+func (t twtxtTwt) Hash(feedURL string) string {
+	payload := fmt.Sprintf("%s\n%s\n%s", feedURL, t.Created.Format(time.RFC3339), t.Text)
+	sum := blake2b.Sum256([]byte(payload))
+	hash := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(sum[:]))
+
+	return hash[len(hash)-7:]
+}
+
+// parseTwtxt reads the metadata and twts of a twtxt.txt feed. The first nick
+// and url metadata values are used.
+func parseTwtxt(body []byte) twtxtFeed {
+	var feed twtxtFeed
+
+	scan := bufio.NewScanner(bytes.NewReader(body))
+	for scan.Scan() {
+		line := strings.TrimSpace(scan.Text())
+
+		if strings.HasPrefix(line, "#") {
+			sp := strings.SplitN(strings.TrimPrefix(line, "#"), "=", 2)
+			if len(sp) != 2 {
+				continue
+			}
+			key, value := strings.TrimSpace(sp[0]), strings.TrimSpace(sp[1])
+			switch {
+			case key == "nick" && feed.Nick == "":
+				feed.Nick = value
+			case key == "url" && feed.URL == "":
+				feed.URL = value
+			}
+			continue
+		}
+
+		sp := strings.SplitN(line, "\t", 2)
+		if len(sp) != 2 {
+			continue
+		}
+		created, err := time.Parse(time.RFC3339, strings.TrimSpace(sp[0]))
+		if err != nil {
+			continue
+		}
+		feed.Twts = append(feed.Twts, twtxtTwt{created, sp[1]})
+	}
+
+	return feed
+}
+
+// twtxtNick guesses the nick from the feed path (ex. /user/nick/twtxt.txt).
+func twtxtNick(p string) string {
+	dir := path.Base(path.Dir(p))
+	if dir == "/" || dir == "." {
+		return "..."
+	}
+
+	return dir
+}
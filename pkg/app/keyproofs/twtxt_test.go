@@ -0,0 +1,51 @@
+package app_keyproofs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTwtxt(t *testing.T) {
+	body := []byte("# nick = alice\n" +
+		"# url = https://example.com/twtxt.txt\n" +
+		"# url = https://mirror.example.com/twtxt.txt\n" +
+		"# description: no equals sign\n" +
+		"\n" +
+		"2020-11-13T16:13:22+01:00\tHello World! 😊\n" +
+		"not a twt\n" +
+		"2020-11-14T08:00:00Z\topenpgp4fpr:" + testFingerprint + "\n")
+
+	feed := parseTwtxt(body)
+	if feed.Nick != "alice" {
+		t.Errorf("nick = %q, want alice", feed.Nick)
+	}
+	if feed.URL != "https://example.com/twtxt.txt" {
+		t.Errorf("url = %q, want the first url", feed.URL)
+	}
+	if len(feed.Twts) != 2 {
+		t.Fatalf("got %d twts, want 2", len(feed.Twts))
+	}
+
+	twt := feed.Twts[0]
+	if want := time.Date(2020, 11, 13, 15, 13, 22, 0, time.UTC); !twt.Created.Equal(want) {
+		t.Errorf("created = %v, want %v", twt.Created, want)
+	}
+	if twt.Text != "Hello World! 😊" {
+		t.Errorf("text = %q", twt.Text)
+	}
+}
+
+func TestTwtxtHash(t *testing.T) {
+	feed := parseTwtxt([]byte("2020-11-13T16:13:22+01:00\tHello World! 😊\n"))
+	if len(feed.Twts) != 1 {
+		t.Fatalf("got %d twts, want 1", len(feed.Twts))
+	}
+
+	// The hash covers the timestamp as written, keeping its offset.
+	if got := feed.Twts[0].Hash("https://example.com/twtxt.txt"); got != "swncyvq" {
+		t.Errorf("hash = %q, want swncyvq", got)
+	}
+	if got := feed.Twts[0].Hash("https://example.org/twtxt.txt"); got == "swncyvq" {
+		t.Error("hash does not depend on the feed url")
+	}
+}
@@ -0,0 +1,66 @@
+package app_keyproofs
+
+import (
+	"context"
+	"encoding/xml"
+
+	app_vcard "github.com/sour-is/keyproofs/pkg/app/vcard"
+)
+
+// VCardGetter requests vcards over an XMPP connection.
+type VCardGetter interface {
+	GetXMPPVCard(context.Context, string) (*app_vcard.VCard, error)
+}
+
+type contextKey struct{ string }
+
+var vcardKey = contextKey{"vcard"}
+
+// WithVCard sets the XMPP connection used to verify xmpp proofs. Without one
+// proofs are verified over http using the xmpp-url config.
+func WithVCard(ctx context.Context, vc VCardGetter) context.Context {
+	return context.WithValue(ctx, vcardKey, vc)
+}
+
+func vcardFromContext(ctx context.Context) VCardGetter {
+	if vc, ok := ctx.Value(vcardKey).(VCardGetter); ok {
+		return vc
+	}
+
+	return nil
+}
+
+type xmppResolve struct {
+	proof Proof
+	jid   string
+	url   string
+}
+
+func (r *xmppResolve) Resolve(ctx context.Context) error {
+	var vcard *app_vcard.VCard
+
+	if vc := vcardFromContext(ctx); vc != nil {
+		var err error
+		if vcard, err = vc.GetXMPPVCard(ctx, r.jid); err != nil {
+			return r.proof.setStatus(err)
+		}
+	} else {
+		body, err := getHTTP(ctx, r.url, nil)
+		if err != nil {
+			return r.proof.setStatus(err)
+		}
+
+		vcard = app_vcard.NewVCard()
+		if err = xml.Unmarshal(body, vcard); err != nil {
+			return r.proof.setStatus(err)
+		}
+	}
+
+	r.proof.Nick = vcard.NickName
+	r.proof.FullName = vcard.FullName
+
+	return r.proof.setStatus(checkClaim(r.proof.Fingerprint, []string{vcard.Description, vcard.Note}))
+}
+func (r *xmppResolve) Proof() *Proof {
+	return &r.proof
+}
@@ -22,6 +22,11 @@ func New(ctx context.Context, xmppConfig *xmpp.Config) (*app, error) {
 
 	return &app{conn: conn}, nil
 }
+
+// GetXMPPVCard requests the vcard for jid over the app connection.
+func (app *app) GetXMPPVCard(ctx context.Context, jid string) (*VCard, error) {
+	return app.conn.GetXMPPVCard(ctx, jid)
+}
 func (app *app) Routes(r *chi.Mux) {
 	r.MethodFunc("GET", "/vcard/{jid}", app.getVCard)
 }
@@ -11,6 +11,7 @@ type VCard struct {
 	FullName    string   `xml:"FN"`
 	NickName    string   `xml:"NICKNAME"`
 	Description string   `xml:"DESC"`
+	Note        string   `xml:"NOTE"`
 	URL         string   `xml:"URL"`
 }
 
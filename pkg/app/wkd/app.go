@@ -308,6 +308,12 @@ func (app *wkdApp) postKey(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !e.HasEmail() {
+		writeText(w, http.StatusBadRequest, "ERR NO EMAIL")
+
+		return
+	}
+
 	fname := filepath.Join(app.path, "keys", e.Primary.Address)
 
 	f, err := os.Open(fname)
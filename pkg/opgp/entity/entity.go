@@ -1,11 +1,15 @@
 package entity
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"net/mail"
+	"sort"
+	"time"
 
 	"github.com/sour-is/crypto/openpgp"
+	"github.com/sour-is/crypto/openpgp/errors"
 	"github.com/sour-is/crypto/openpgp/packet"
 )
 
@@ -15,75 +19,247 @@ func (k Key) Key() interface{} {
 	return k
 }
 
+// Nobody is the primary address of an entity without any email identities.
+const Nobody = "nobody@nodomain.xyz"
+
+// ProofNotations are the signature notations proofs are read from.
+var ProofNotations = []string{"proof@ariadne.id", "proof@metacode.biz"}
+
 type Entity struct {
 	Primary       *mail.Address
 	SelfSignature *packet.Signature
 	Emails        []*mail.Address
+	Identities    []*Identity
+	Key           *KeyInfo
+	Subkeys       []*KeyInfo
+	Photos        [][]byte // jpeg images from photo user ids with a valid self-signature
 	Fingerprint   string
 	Proofs        []string
+	Notations     map[string]string // notation each proof was read from
 	ArmorText     string
+	Source        string // name of the key source that served the key
 	entity        *openpgp.Entity
 }
 
+// Identity is a user id and the proofs claimed by its self-signature. Address is
+// nil if the user id is not an email address. If the self-signature could not be
+// verified it is dropped along with its proofs.
+type Identity struct {
+	Name          string
+	Address       *mail.Address
+	SelfSignature *packet.Signature
+	Verified      bool
+	Proofs        []string
+}
+
+// HasEmail reports if the entity has an email identity.
+func (e *Entity) HasEmail() bool {
+	return e.Primary != nil && e.Primary.Address != Nobody
+}
+
+// Warnings lists problems with the primary key that users should be told about.
+func (e *Entity) Warnings() []string {
+	if e.Key == nil {
+		return nil
+	}
+
+	var lis []string
+	if e.Key.Revoked {
+		msg := "This key has been revoked"
+		if e.Key.RevocationReason != "" {
+			msg += ": " + e.Key.RevocationReason
+		}
+		lis = append(lis, msg)
+	}
+	if e.Key.Expired() {
+		lis = append(lis, fmt.Sprintf("This key expired on %s", e.Key.Expires.Format("2006-01-02")))
+	}
+	if n := len(e.Identities) - e.verifiedCount(); n > 0 {
+		lis = append(lis, fmt.Sprintf("%d of %d user IDs have no valid self-signature, their proofs are ignored", n, len(e.Identities)))
+	}
+
+	return lis
+}
+
+// Verified reports if the self-signature of every user id is valid.
+func (e *Entity) Verified() bool {
+	return len(e.Identities) > 0 && e.verifiedCount() == len(e.Identities)
+}
+
+func (e *Entity) verifiedCount() int {
+	n := 0
+	for _, id := range e.Identities {
+		if id.Verified {
+			n++
+		}
+	}
+	return n
+}
+
 func (e *Entity) Serialize(f io.Writer) error {
 	return e.entity.Serialize(f)
 }
 
-func GetOne(lis openpgp.EntityList) (*Entity, error) {
-	entity := &Entity{}
-	var err error
+// Read reads the packets of a single key as split by SplitKeys. The openpgp
+// parser rejects the whole key if any user id self-signature is invalid, so
+// those user ids are dropped first and kept as unverified identities.
+func Read(key []byte) (*Entity, error) {
+	packets, err := readPackets(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(packets) == 0 || packets[0].tag != tagPublicKey {
+		return nil, errors.StructuralError("first packet was not a public key")
+	}
 
+	p, err := packet.Read(bytes.NewReader(packets[0].data))
+	if err != nil {
+		return nil, err
+	}
+	pub, ok := p.(*packet.PublicKey)
+	if !ok {
+		return nil, errors.StructuralError("first packet was not a public key")
+	}
+
+	kept, unverified, photos := checkSelfSignatures(pub, packets)
+	e, err := openpgp.ReadEntity(packet.NewReader(bytes.NewReader(kept)))
+	if err != nil {
+		return nil, err
+	}
+
+	entity := newEntity(e, unverified, subkeyBindings(packets))
+	entity.Photos = photos
+
+	return entity, nil
+}
+
+func GetOne(lis openpgp.EntityList) (*Entity, error) {
 	for _, e := range lis {
-		if e == nil {
-			continue
-		}
-		if e.PrimaryKey == nil {
-			continue
+		if e != nil && e.PrimaryKey != nil {
+			return newEntity(e, nil, nil), nil
 		}
+	}
+
+	return newEntity(nil, nil, nil), nil
+}
+
+// newEntity reads the identities and proofs of e. The parser only keeps user
+// ids with a valid self-signature, those in unverified are listed without.
+// Bindings holds the subkey binding signatures the parser dropped for a
+// revocation.
+func newEntity(e *openpgp.Entity, unverified []string, bindings map[uint64]*packet.Signature) *Entity {
+	entity := &Entity{Notations: make(map[string]string)}
+	var displayName string
 
+	if e != nil {
 		entity.entity = e
 		entity.Fingerprint = fmt.Sprintf("%X", e.PrimaryKey.Fingerprint)
 
+		sigs := make(map[string]*packet.Signature, len(e.Identities)+len(unverified))
 		for name, ident := range e.Identities {
-			// Pick first identity
-			if entity.Primary == nil {
-				entity.Primary, err = mail.ParseAddress(name)
-				if err != nil {
-					return entity, err
-				}
+			sigs[name] = ident.SelfSignature
+		}
+		for _, name := range unverified {
+			if _, ok := sigs[name]; !ok {
+				sigs[name] = nil
 			}
-			// If one is marked primary use that
-			if ident.SelfSignature != nil && ident.SelfSignature.IsPrimaryId != nil && *ident.SelfSignature.IsPrimaryId {
-				entity.Primary, err = mail.ParseAddress(name)
-				if err != nil {
-					return entity, err
-				}
+		}
 
-			} else {
-				var email *mail.Address
-				if email, err = mail.ParseAddress(name); err != nil {
-					return entity, err
-				}
-				if email.Address != entity.Primary.Address {
-					entity.Emails = append(entity.Emails, email)
-				}
+		var primarySig *packet.Signature
+		for i, name := range sortIdentities(sigs) {
+			sig := sigs[name]
+
+			// User ids that are not an email address are kept for display only.
+			email, _ := mail.ParseAddress(name)
+			if i == 0 {
+				displayName = name
+				primarySig = sig
+			}
+
+			// The first verified email identity in order is the primary.
+			switch {
+			case email == nil || sig == nil:
+			case entity.Primary == nil:
+				entity.Primary = email
+			case email.Address != entity.Primary.Address:
+				entity.Emails = append(entity.Emails, email)
+			}
+
+			id := &Identity{Name: name, Address: email, SelfSignature: sig, Verified: sig != nil}
+			entity.Identities = append(entity.Identities, id)
+
+			if sig == nil {
+				continue
 			}
+			if entity.SelfSignature == nil || entity.SelfSignature.CreationTime.Before(sig.CreationTime) {
+				entity.SelfSignature = sig
+			}
+
+			// Get proofs and append to lists skipping any seen before.
+			seen := make(map[string]bool)
+			for _, notation := range ProofNotations {
+				for _, proof := range sig.NotationData[notation] {
+					if seen[proof] {
+						continue
+					}
+					seen[proof] = true
+					id.Proofs = append(id.Proofs, proof)
 
-			// If identity is self signed read notation data.
-			if ident.SelfSignature != nil && ident.SelfSignature.NotationData != nil {
-				entity.SelfSignature = ident.SelfSignature
-				// Get proofs and append to list.
-				if proofs, ok := ident.SelfSignature.NotationData["proof@metacode.biz"]; ok {
-					entity.Proofs = append(entity.Proofs, proofs...)
+					if _, ok := entity.Notations[proof]; !ok {
+						entity.Notations[proof] = notation
+						entity.Proofs = append(entity.Proofs, proof)
+					}
 				}
 			}
 		}
-		break
+
+		entity.Key = newKeyInfo(e.PrimaryKey, primarySig, e.Revocations)
+		for _, sub := range e.Subkeys {
+			sig, revocations := sub.Sig, []*packet.Signature(nil)
+			if binding := bindings[sub.PublicKey.KeyId]; binding != nil && sig.SigType == packet.SigTypeSubkeyRevocation {
+				sig, revocations = binding, []*packet.Signature{sub.Sig}
+			}
+			entity.Subkeys = append(entity.Subkeys, newKeyInfo(sub.PublicKey, sig, revocations))
+		}
 	}
 
 	if entity.Primary == nil {
-		entity.Primary, _ = mail.ParseAddress("nobody@nodomain.xyz")
+		entity.Primary = &mail.Address{Name: displayName, Address: Nobody}
+	}
+
+	return entity
+}
+
+// sortIdentities orders the identity names following the primary user id rules.
+// Identities flagged primary come first, then the newest self-signature, then
+// by name.
+func sortIdentities(lis map[string]*packet.Signature) []string {
+	names := make([]string, 0, len(lis))
+	for name := range lis {
+		names = append(names, name)
 	}
 
-	return entity, err
+	sort.Slice(names, func(i, j int) bool {
+		a, b := lis[names[i]], lis[names[j]]
+		if pa, pb := isPrimary(a), isPrimary(b); pa != pb {
+			return pa
+		}
+		if ta, tb := created(a), created(b); !ta.Equal(tb) {
+			return ta.After(tb)
+		}
+		return names[i] < names[j]
+	})
+
+	return names
+}
+
+func isPrimary(sig *packet.Signature) bool {
+	return sig != nil && sig.IsPrimaryId != nil && *sig.IsPrimaryId
+}
+
+func created(sig *packet.Signature) time.Time {
+	if sig == nil {
+		return time.Time{}
+	}
+	return sig.CreationTime
 }
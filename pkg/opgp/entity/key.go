@@ -0,0 +1,105 @@
+package entity
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"time"
+
+	"github.com/sour-is/crypto/openpgp/packet"
+)
+
+// KeyInfo describes the primary key or a subkey.
+type KeyInfo struct {
+	KeyID            string     `json:"key_id"`
+	Fingerprint      string     `json:"fingerprint"`
+	Algorithm        string     `json:"algorithm"`
+	BitLength        int        `json:"bit_length,omitempty"`
+	Curve            string     `json:"curve,omitempty"`
+	Flags            []string   `json:"flags"`
+	Created          time.Time  `json:"created"`
+	Expires          *time.Time `json:"expires,omitempty"`
+	Revoked          bool       `json:"revoked"`
+	RevocationReason string     `json:"revocation_reason,omitempty"`
+}
+
+// Expired reports if the key has expired.
+func (k *KeyInfo) Expired() bool {
+	return k.Expires != nil && time.Now().After(*k.Expires)
+}
+
+var algorithmNames = map[packet.PublicKeyAlgorithm]string{
+	packet.PubKeyAlgoRSA:            "RSA",
+	packet.PubKeyAlgoRSAEncryptOnly: "RSA",
+	packet.PubKeyAlgoRSASignOnly:    "RSA",
+	packet.PubKeyAlgoElGamal:        "ElGamal",
+	packet.PubKeyAlgoDSA:            "DSA",
+	packet.PubKeyAlgoECDH:           "ECDH",
+	packet.PubKeyAlgoECDSA:          "ECDSA",
+}
+
+var revocationReasons = map[uint8]string{
+	0:  "no reason specified",
+	1:  "key is superseded",
+	2:  "key material has been compromised",
+	3:  "key is retired and no longer used",
+	32: "user id information is no longer valid",
+}
+
+// newKeyInfo reads the details of pub. The flags and expiry are read from sig,
+// the self-signature or binding signature of the key. A subkey revocation given
+// as sig marks the key revoked without flags or expiry.
+func newKeyInfo(pub *packet.PublicKey, sig *packet.Signature, revocations []*packet.Signature) *KeyInfo {
+	k := &KeyInfo{
+		KeyID:       fmt.Sprintf("%016X", pub.KeyId),
+		Fingerprint: fmt.Sprintf("%X", pub.Fingerprint),
+		Algorithm:   algorithmNames[pub.PubKeyAlgo],
+		Created:     pub.CreationTime,
+	}
+	if k.Algorithm == "" {
+		k.Algorithm = fmt.Sprintf("algorithm %d", pub.PubKeyAlgo)
+	}
+
+	if ec, ok := pub.PublicKey.(*ecdsa.PublicKey); ok && ec.Curve != nil {
+		k.Curve = ec.Curve.Params().Name
+		k.BitLength = ec.Curve.Params().BitSize
+	} else if n, err := pub.BitLength(); err == nil {
+		k.BitLength = int(n)
+	}
+
+	if sig != nil && sig.SigType == packet.SigTypeSubkeyRevocation {
+		revocations = append(revocations, sig)
+		sig = nil
+	}
+	for _, rev := range revocations {
+		k.Revoked = true
+		if rev.RevocationReason != nil {
+			k.RevocationReason = revocationReasons[*rev.RevocationReason]
+		}
+		if rev.RevocationReasonText != "" {
+			k.RevocationReason = rev.RevocationReasonText
+		}
+	}
+
+	if sig == nil {
+		return k
+	}
+
+	if sig.FlagsValid {
+		if sig.FlagCertify {
+			k.Flags = append(k.Flags, "certify")
+		}
+		if sig.FlagSign {
+			k.Flags = append(k.Flags, "sign")
+		}
+		if sig.FlagEncryptCommunications || sig.FlagEncryptStorage {
+			k.Flags = append(k.Flags, "encrypt")
+		}
+	}
+
+	if sig.KeyLifetimeSecs != nil && *sig.KeyLifetimeSecs != 0 {
+		expires := pub.CreationTime.Add(time.Duration(*sig.KeyLifetimeSecs) * time.Second)
+		k.Expires = &expires
+	}
+
+	return k
+}
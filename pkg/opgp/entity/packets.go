@@ -0,0 +1,317 @@
+package entity
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+
+	"github.com/sour-is/crypto/openpgp/errors"
+	"github.com/sour-is/crypto/openpgp/packet"
+)
+
+const (
+	tagSignature     = 2
+	tagPublicKey     = 6
+	tagTrust         = 12
+	tagUserID        = 13
+	tagPublicSubkey  = 14
+	tagUserAttribute = 17
+)
+
+// rawPacket is a packet as it was read. Passing keys on as read keeps the
+// packets the openpgp parser does not know, which re-serializing would drop.
+type rawPacket struct {
+	tag  uint8
+	data []byte // header and body
+	body []byte
+}
+
+// SplitKeys splits a binary keyring into the packets of each public key.
+// Packets before the first key are skipped.
+func SplitKeys(data []byte) ([][]byte, error) {
+	packets, err := readPackets(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys [][]byte
+	for _, p := range packets {
+		if p.tag == tagPublicKey {
+			keys = append(keys, nil)
+		}
+		if len(keys) == 0 {
+			continue
+		}
+
+		last := len(keys) - 1
+		keys[last] = append(keys[last], p.data...)
+	}
+
+	return keys, nil
+}
+
+// checkSelfSignatures drops the self-signatures over user ids that do not
+// verify and the user ids left without one. It returns the packets kept and
+// the names of the dropped user ids. The openpgp parser ignores photo user ids,
+// so their self-signatures are checked here and the jpeg images of the valid
+// ones returned.
+func checkSelfSignatures(pub *packet.PublicKey, packets []rawPacket) (kept []byte, dropped []string, photos [][]byte) {
+	for i := 0; i < len(packets); {
+		head := packets[i]
+		j := i + 1
+		for j < len(packets) && (packets[j].tag == tagSignature || packets[j].tag == tagTrust) {
+			j++
+		}
+		sigs := packets[i+1 : j]
+		i = j
+
+		if head.tag == tagUserAttribute {
+			if verifyAttribute(pub, packets[0].body, head.body, sigs) {
+				photos = append(photos, readImages(head)...)
+				kept = append(kept, head.data...)
+				for _, p := range sigs {
+					kept = append(kept, p.data...)
+				}
+			}
+			continue
+		}
+
+		if head.tag != tagUserID {
+			kept = append(kept, head.data...)
+			for _, p := range sigs {
+				kept = append(kept, p.data...)
+			}
+			continue
+		}
+
+		id := string(head.body)
+		var valid []rawPacket
+		var verified bool
+		for _, p := range sigs {
+			sig := selfSignature(pub, p)
+			if sig == nil {
+				valid = append(valid, p)
+				continue
+			}
+			if err := pub.VerifyUserIdSignature(id, pub, sig); err == nil {
+				verified = true
+				valid = append(valid, p)
+			}
+		}
+
+		if !verified {
+			dropped = append(dropped, id)
+			continue
+		}
+
+		kept = append(kept, head.data...)
+		for _, p := range valid {
+			kept = append(kept, p.data...)
+		}
+	}
+
+	return kept, dropped, photos
+}
+
+// verifyAttribute reports if any self-signature over the user attribute is
+// valid. The signed data is laid out as in RFC 4880, section 5.2.4.
+func verifyAttribute(pub *packet.PublicKey, key, attr []byte, sigs []rawPacket) bool {
+	for _, p := range sigs {
+		sig := selfSignature(pub, p)
+		if sig == nil || !sig.Hash.Available() {
+			continue
+		}
+
+		h := sig.Hash.New()
+		h.Write([]byte{0x99, byte(len(key) >> 8), byte(len(key))})
+		h.Write(key)
+
+		var buf [5]byte
+		buf[0] = 0xd1
+		binary.BigEndian.PutUint32(buf[1:], uint32(len(attr)))
+		h.Write(buf[:])
+		h.Write(attr)
+
+		if pub.VerifySignature(h, sig) == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// readImages returns the images of a user attribute with a jpeg image header.
+// Version 1 headers are 16 bytes with the encoding in the fourth.
+func readImages(p rawPacket) [][]byte {
+	pkt, err := packet.Read(bytes.NewReader(p.data))
+	if err != nil {
+		return nil
+	}
+	attr, ok := pkt.(*packet.UserAttribute)
+	if !ok {
+		return nil
+	}
+
+	var images [][]byte
+	for _, sp := range attr.Contents {
+		c := sp.Contents
+		if sp.SubType != packet.UserAttrImageSubpacket || len(c) <= 16 {
+			continue
+		}
+		if binary.LittleEndian.Uint16(c[:2]) != 16 || c[2] != 1 || c[3] != 1 {
+			continue
+		}
+
+		images = append(images, c[16:])
+	}
+
+	return images
+}
+
+// selfSignature returns p if it is a certification by pub the openpgp parser
+// would check as a self-signature.
+func selfSignature(pub *packet.PublicKey, p rawPacket) *packet.Signature {
+	if p.tag != tagSignature {
+		return nil
+	}
+
+	pkt, err := packet.Read(bytes.NewReader(p.data))
+	if err != nil {
+		return nil
+	}
+	sig, ok := pkt.(*packet.Signature)
+	if !ok {
+		return nil
+	}
+
+	if sig.SigType != packet.SigTypePositiveCert && sig.SigType != packet.SigTypeGenericCert {
+		return nil
+	}
+	if sig.IssuerKeyId == nil || *sig.IssuerKeyId != pub.KeyId {
+		return nil
+	}
+
+	return sig
+}
+
+// subkeyBindings returns the newest binding signature of each subkey by key
+// id. The openpgp parser replaces it with the revocation of a revoked subkey.
+func subkeyBindings(packets []rawPacket) map[uint64]*packet.Signature {
+	bindings := make(map[uint64]*packet.Signature)
+
+	var sub *packet.PublicKey
+	for _, p := range packets {
+		switch p.tag {
+		case tagPublicSubkey:
+			sub = nil
+			if pkt, err := packet.Read(bytes.NewReader(p.data)); err == nil {
+				sub, _ = pkt.(*packet.PublicKey)
+			}
+
+		case tagSignature:
+			if sub == nil {
+				continue
+			}
+			pkt, err := packet.Read(bytes.NewReader(p.data))
+			if err != nil {
+				continue
+			}
+			sig, ok := pkt.(*packet.Signature)
+			if !ok || sig.SigType != packet.SigTypeSubkeyBinding {
+				continue
+			}
+			if last := bindings[sub.KeyId]; last == nil || last.CreationTime.Before(sig.CreationTime) {
+				bindings[sub.KeyId] = sig
+			}
+
+		case tagTrust:
+		default:
+			sub = nil
+		}
+	}
+
+	return bindings
+}
+
+// readPackets splits data into its packets. Keys can not use partial lengths
+// so the header of each packet has its full length.
+func readPackets(data []byte) ([]rawPacket, error) {
+	var lis []rawPacket
+	for len(data) > 0 {
+		p, err := readPacket(data)
+		if err != nil {
+			return nil, err
+		}
+
+		lis = append(lis, p)
+		data = data[len(p.data):]
+	}
+
+	return lis, nil
+}
+
+func readPacket(data []byte) (p rawPacket, err error) {
+	if data[0]&0x80 == 0 {
+		return p, errors.StructuralError("tag byte does not have MSB set")
+	}
+
+	var hdr, length int
+	if data[0]&0x40 == 0 {
+		// Old format packets have the size of the length in the tag byte.
+		p.tag = (data[0] & 0x3f) >> 2
+		switch data[0] & 3 {
+		case 0:
+			hdr = 2
+		case 1:
+			hdr = 3
+		case 2:
+			hdr = 5
+		default:
+			hdr, length = 1, len(data)-1
+		}
+		if len(data) < hdr {
+			return p, io.ErrUnexpectedEOF
+		}
+
+		switch hdr {
+		case 2:
+			length = int(data[1])
+		case 3:
+			length = int(binary.BigEndian.Uint16(data[1:3]))
+		case 5:
+			length = int(binary.BigEndian.Uint32(data[1:5]))
+		}
+	} else {
+		p.tag = data[0] & 0x3f
+		if len(data) < 2 {
+			return p, io.ErrUnexpectedEOF
+		}
+
+		switch l := int(data[1]); {
+		case l < 192:
+			hdr, length = 2, l
+		case l < 224:
+			hdr = 3
+			if len(data) < hdr {
+				return p, io.ErrUnexpectedEOF
+			}
+			length = (l-192)<<8 + int(data[2]) + 192
+		case l == 255:
+			hdr = 6
+			if len(data) < hdr {
+				return p, io.ErrUnexpectedEOF
+			}
+			length = int(binary.BigEndian.Uint32(data[2:6]))
+		default:
+			return p, errors.StructuralError("partial length in key packet")
+		}
+	}
+
+	if length < 0 || len(data)-hdr < length {
+		return p, io.ErrUnexpectedEOF
+	}
+
+	p.data = data[:hdr+length]
+	p.body = data[hdr : hdr+length]
+	return p, nil
+}
@@ -0,0 +1,61 @@
+package opgp
+
+import (
+	"net/http"
+
+	lru "github.com/hashicorp/golang-lru"
+
+	"github.com/sour-is/keyproofs/pkg/opgp/entity"
+)
+
+// httpClient is shared by all remote key fetches.
+var httpClient = &http.Client{}
+
+// keyCache holds the validators of fetched keys so a refresh can send a
+// conditional request and reuse the parsed entity when it is not modified.
+var keyCache, _ = lru.New(1024)
+
+type cachedKey struct {
+	etag         string
+	lastModified string
+	entity       *entity.Entity
+}
+
+func keyCacheKey(url, email string) string {
+	return url + " " + email
+}
+
+// setConditional adds the validators of the cached key to req and returns it.
+func setConditional(req *http.Request, key string) *cachedKey {
+	v, ok := keyCache.Get(key)
+	if !ok {
+		return nil
+	}
+	c := v.(*cachedKey)
+
+	if c.etag != "" {
+		req.Header.Set("If-None-Match", c.etag)
+	}
+	if c.lastModified != "" {
+		req.Header.Set("If-Modified-Since", c.lastModified)
+	}
+	return c
+}
+
+// storeKey keeps the entity if the response has validators to check it with.
+func storeKey(key string, resp *http.Response, e *entity.Entity) {
+	etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+	if etag == "" && lastModified == "" {
+		keyCache.Remove(key)
+		return
+	}
+
+	keyCache.Add(key, &cachedKey{etag, lastModified, e})
+}
+
+// copyEntity returns a shallow copy so callers can set fields like Source
+// without changing the cached value.
+func copyEntity(e *entity.Entity) *entity.Entity {
+	c := *e
+	return &c
+}
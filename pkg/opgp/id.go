@@ -0,0 +1,65 @@
+package opgp
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+)
+
+var ErrInvalidID = errors.New("invalid key id")
+
+// IDKind is the kind of id a key is looked up by.
+type IDKind int
+
+const (
+	IDEmail IDKind = iota + 1
+	IDKeyID
+	IDFingerprintV4
+)
+
+// ParseID checks that id is an email address, a 16 hex digit long key id or a
+// 40 hex digit v4 fingerprint. Hex ids may have a 0x prefix and are returned in
+// upper case. The openpgp parser only reads v4 keys, so 64 hex digit v5
+// fingerprints are refused.
+func ParseID(id string) (IDKind, string, error) {
+	if strings.ContainsRune(id, '@') {
+		email, err := mail.ParseAddress(id)
+		if err != nil {
+			return 0, "", fmt.Errorf("%w: %s", ErrInvalidID, err)
+		}
+		return IDEmail, email.Address, nil
+	}
+
+	hex := strings.ToUpper(strings.TrimPrefix(strings.TrimPrefix(id, "0x"), "0X"))
+	if !isHex(hex) {
+		return 0, "", fmt.Errorf("%w: %q is not an email address or hex key id", ErrInvalidID, id)
+	}
+
+	switch len(hex) {
+	case 16:
+		return IDKeyID, hex, nil
+	case 40:
+		return IDFingerprintV4, hex, nil
+	case 64:
+		return 0, "", fmt.Errorf("%w: v5 keys are not supported", ErrInvalidID)
+	}
+
+	return 0, "", fmt.Errorf("%w: %d hex digits, expected 16 or 40", ErrInvalidID, len(hex))
+}
+
+func isHex(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for _, r := range s {
+		switch r {
+		case '0', '1', '2', '3', '4', '5', '6', '7', '8', '9', 'A', 'B', 'C', 'D', 'E', 'F':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
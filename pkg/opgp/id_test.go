@@ -0,0 +1,50 @@
+package opgp
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseID(t *testing.T) {
+	v4 := "3E0F4D5BA4FDAE3B0A1D3E9BB5B8F1C0D4A6E2F7"
+	v5 := strings.Repeat("0123456789ABCDEF", 4)
+
+	tests := []struct {
+		id   string
+		kind IDKind
+		want string
+		err  error
+	}{
+		{"user@example.com", IDEmail, "user@example.com", nil},
+		{"User <user@example.com>", IDEmail, "user@example.com", nil},
+		{"B5B8F1C0D4A6E2F7", IDKeyID, "B5B8F1C0D4A6E2F7", nil},
+		{"0xb5b8f1c0d4a6e2f7", IDKeyID, "B5B8F1C0D4A6E2F7", nil},
+		{v4, IDFingerprintV4, v4, nil},
+		{"0x" + strings.ToLower(v4), IDFingerprintV4, v4, nil},
+		{"0X" + v4, IDFingerprintV4, v4, nil},
+		{v5, 0, "", ErrInvalidID},
+		{"0x" + v5, 0, "", ErrInvalidID},
+		{"", 0, "", ErrInvalidID},
+		{"0x", 0, "", ErrInvalidID},
+		{"D4A6E2F7", 0, "", ErrInvalidID},
+		{"B5B8F1C0D4A6E2F", 0, "", ErrInvalidID},
+		{v4[:39], 0, "", ErrInvalidID},
+		{v4 + "0", 0, "", ErrInvalidID},
+		{v5[:63], 0, "", ErrInvalidID},
+		{"G5B8F1C0D4A6E2F7", 0, "", ErrInvalidID},
+		{"not an id", 0, "", ErrInvalidID},
+		{"user@", 0, "", ErrInvalidID},
+	}
+
+	for _, tt := range tests {
+		kind, id, err := ParseID(tt.id)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("ParseID(%q) error = %v, want %v", tt.id, err, tt.err)
+			continue
+		}
+		if kind != tt.kind || id != tt.want {
+			t.Errorf("ParseID(%q) = %v, %q, want %v, %q", tt.id, kind, id, tt.kind, tt.want)
+		}
+	}
+}
@@ -0,0 +1,391 @@
+package opgp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/mail"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"go.uber.org/multierr"
+
+	"github.com/sour-is/keyproofs/pkg/opgp/entity"
+)
+
+var ErrNotFound = errors.New("key not found")
+
+// KeySource looks up a key by email address, key id or fingerprint. Sources that do not
+// support the kind of id return ErrNotFound.
+type KeySource interface {
+	Name() string
+	GetKey(ctx context.Context, id string) (*entity.Entity, error)
+}
+
+// DefaultSources are used by GetKey.
+var DefaultSources = Chain{WKD{}, VKS{"https://keys.openpgp.org"}}
+
+// Chain tries each source in order and returns the first key found. The
+// entity records the name of the source that served it.
+type Chain []KeySource
+
+func (c Chain) Name() string {
+	names := make([]string, len(c))
+	for i, src := range c {
+		names[i] = src.Name()
+	}
+	return strings.Join(names, " ")
+}
+
+func (c Chain) GetKey(ctx context.Context, id string) (*entity.Entity, error) {
+	if _, _, err := ParseID(id); err != nil {
+		return nil, err
+	}
+
+	var errs error
+	for _, src := range c {
+		e, err := src.GetKey(ctx, id)
+		if err == nil {
+			e.Source = src.Name()
+			return e, nil
+		}
+
+		log.Ctx(ctx).Debug().Str("source", src.Name()).Err(err).Msg("key lookup")
+		errs = multierr.Append(errs, fmt.Errorf("%s: %w", src.Name(), err))
+	}
+
+	if errs == nil {
+		return nil, ErrNotFound
+	}
+	return nil, errs
+}
+
+// DefaultWait is how long Parallel waits for a preferred source by default.
+const DefaultWait = 500 * time.Millisecond
+
+// Parallel queries all sources at once. Sources earlier in the list are
+// preferred, once any source has found the key the more preferred sources are
+// given up to Wait to answer. The requests still running are cancelled.
+type Parallel struct {
+	Sources Chain
+	Wait    time.Duration
+}
+
+func (p Parallel) Name() string {
+	return p.Sources.Name()
+}
+
+func (p Parallel) GetKey(ctx context.Context, id string) (*entity.Entity, error) {
+	if _, _, err := ParseID(id); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		i   int
+		e   *entity.Entity
+		err error
+	}
+
+	results := make(chan result, len(p.Sources))
+	for i, src := range p.Sources {
+		go func(i int, src KeySource) {
+			e, err := src.GetKey(ctx, id)
+			results <- result{i, e, err}
+		}(i, src)
+	}
+
+	answered := make([]bool, len(p.Sources))
+	errs := make([]error, len(p.Sources))
+	var best *entity.Entity
+	bestIdx := len(p.Sources)
+
+	var wait <-chan time.Time
+	for pending := len(p.Sources); pending > 0; {
+		select {
+		case r := <-results:
+			pending--
+			answered[r.i] = true
+			errs[r.i] = r.err
+
+			if r.err == nil && r.i < bestIdx {
+				best, bestIdx = r.e, r.i
+				best.Source = p.Sources[r.i].Name()
+			}
+			if best == nil {
+				continue
+			}
+			if allTrue(answered[:bestIdx]) {
+				return best, nil
+			}
+			if wait == nil {
+				timer := time.NewTimer(p.Wait)
+				defer timer.Stop()
+				wait = timer.C
+			}
+
+		case <-wait:
+			return best, nil
+
+		case <-ctx.Done():
+			if best != nil {
+				return best, nil
+			}
+			return nil, ctx.Err()
+		}
+	}
+
+	if best != nil {
+		return best, nil
+	}
+
+	var err error
+	for i, e := range errs {
+		err = multierr.Append(err, fmt.Errorf("%s: %w", p.Sources[i].Name(), e))
+	}
+	if err == nil {
+		return nil, ErrNotFound
+	}
+	return nil, err
+}
+
+func allTrue(lis []bool) bool {
+	for _, ok := range lis {
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseSources reads a space separated list of sources. Each is a source name
+// with an optional argument. (ex. "wkd vks=https://keys.openpgp.org keyring=/path")
+func ParseSources(spec string) (Chain, error) {
+	var c Chain
+	for _, field := range strings.Fields(spec) {
+		sp := strings.SplitN(field, "=", 2)
+		name, arg := sp[0], ""
+		if len(sp) == 2 {
+			arg = sp[1]
+		}
+
+		switch name {
+		case "wkd":
+			c = append(c, WKD{})
+		case "vks":
+			c = append(c, VKS{orDefault(arg, "https://keys.openpgp.org")})
+		case "hkp":
+			if arg == "" {
+				return nil, fmt.Errorf("key source %s: missing url", name)
+			}
+			c = append(c, HKP{arg})
+		case "local-wkd":
+			c = append(c, LocalWKD{orDefault(arg, "pub")})
+		case "keyring":
+			if arg == "" {
+				return nil, fmt.Errorf("key source %s: missing path", name)
+			}
+			c = append(c, Keyring{arg})
+		default:
+			return nil, fmt.Errorf("unknown key source: %s", name)
+		}
+	}
+
+	if len(c) == 0 {
+		return DefaultSources, nil
+	}
+	return c, nil
+}
+
+// WKD looks up email addresses from the web key directory of their domain.
+type WKD struct{}
+
+func (WKD) Name() string { return "wkd" }
+func (WKD) GetKey(ctx context.Context, id string) (*entity.Entity, error) {
+	kind, id, err := ParseID(id)
+	if err != nil || kind != IDEmail {
+		return nil, ErrNotFound
+	}
+	email := &mail.Address{Address: id}
+
+	// The advanced method is used when its subdomain exists, otherwise direct.
+	domain := id[strings.LastIndex(id, "@")+1:]
+	_, err = net.DefaultResolver.LookupHost(ctx, "openpgpkey."+domain)
+	addr, policy := wkdAddr(email, err == nil)
+
+	if err := checkHTTP(ctx, policy); err != nil {
+		return nil, fmt.Errorf("%w: no wkd policy: %v", ErrNotFound, err)
+	}
+
+	return getEntityHTTP(ctx, addr, false, IDEmail, email.Address)
+}
+
+// VKS looks up keys from a verifying keyserver like keys.openpgp.org.
+type VKS struct {
+	URL string
+}
+
+func (s VKS) Name() string { return "vks " + hostname(s.URL) }
+func (s VKS) GetKey(ctx context.Context, id string) (*entity.Entity, error) {
+	kind, id, err := ParseID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	base := strings.TrimSuffix(s.URL, "/")
+	switch kind {
+	case IDKeyID:
+		return getEntityHTTP(ctx, base+"/vks/v1/by-keyid/"+id, true, kind, id)
+	case IDFingerprintV4:
+		return getEntityHTTP(ctx, base+"/vks/v1/by-fingerprint/"+id, true, kind, id)
+	default:
+		return getEntityHTTP(ctx, base+"/vks/v1/by-email/"+url.QueryEscape(id), true, kind, id)
+	}
+}
+
+// HKP looks up keys from a keyserver using the HKP /pks/lookup api.
+type HKP struct {
+	URL string
+}
+
+func (s HKP) Name() string { return "hkp " + hostname(s.URL) }
+func (s HKP) GetKey(ctx context.Context, id string) (*entity.Entity, error) {
+	kind, id, err := ParseID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	search := "0x" + id
+	if kind == IDEmail {
+		search = id
+	}
+
+	uri := fmt.Sprintf("%s/pks/lookup?op=get&options=mr&search=%s", strings.TrimSuffix(s.URL, "/"), url.QueryEscape(search))
+	return getEntityHTTP(ctx, uri, true, kind, id)
+}
+
+// LocalWKD reads keys for email addresses from the store of the wkd app.
+type LocalWKD struct {
+	Path string
+}
+
+func (s LocalWKD) Name() string { return "local-wkd" }
+func (s LocalWKD) GetKey(ctx context.Context, id string) (*entity.Entity, error) {
+	kind, id, err := ParseID(id)
+	if err != nil || kind != IDEmail {
+		return nil, ErrNotFound
+	}
+
+	for _, name := range []string{id, strings.ToLower(id)} {
+		if name != filepath.Base(name) {
+			return nil, ErrNotFound
+		}
+
+		f, err := os.Open(filepath.Join(s.Path, "keys", name))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		return ReadKey(f, false)
+	}
+
+	return nil, ErrNotFound
+}
+
+// Keyring searches every key in the key files of a directory for a key id,
+// fingerprint or user id with the email address.
+type Keyring struct {
+	Dir string
+}
+
+func (s Keyring) Name() string { return "keyring" }
+func (s Keyring) GetKey(ctx context.Context, id string) (*entity.Entity, error) {
+	kind, id, err := ParseID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := ioutil.ReadDir(s.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, info := range files {
+		if info.IsDir() {
+			continue
+		}
+
+		data, err := ioutil.ReadFile(filepath.Join(s.Dir, info.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		armored := bytes.Contains(data, []byte("-----BEGIN PGP PUBLIC KEY BLOCK-----"))
+		if kind == IDEmail {
+			e, err := ReadKeyFor(bytes.NewReader(data), armored, id)
+			if err == nil {
+				return e, nil
+			}
+			log.Ctx(ctx).Debug().Str("file", info.Name()).Err(err).Msg("keyring")
+			continue
+		}
+
+		lis, err := ReadKeys(bytes.NewReader(data), armored)
+		if err != nil {
+			log.Ctx(ctx).Debug().Str("file", info.Name()).Err(err).Msg("keyring")
+			continue
+		}
+		for _, e := range lis {
+			if matchEntity(e, kind, id) {
+				return e, nil
+			}
+		}
+	}
+
+	return nil, ErrNotFound
+}
+
+// matchEntity reports if the primary key has the key id or fingerprint or the
+// entity has a verified email identity for the address.
+func matchEntity(e *entity.Entity, kind IDKind, id string) bool {
+	switch kind {
+	case IDKeyID:
+		return e.Key != nil && e.Key.KeyID == id
+	case IDFingerprintV4:
+		return strings.EqualFold(e.Fingerprint, id)
+	}
+
+	for _, ident := range e.Identities {
+		if ident.Verified && ident.Address != nil && strings.EqualFold(ident.Address.Address, id) {
+			return true
+		}
+	}
+	return false
+}
+
+func hostname(s string) string {
+	if u, err := url.Parse(s); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return s
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
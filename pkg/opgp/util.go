@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"context"
 	"crypto/sha1"
+	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/mail"
 	"net/url"
@@ -18,121 +20,189 @@ import (
 	"golang.org/x/crypto/openpgp/armor"
 )
 
-func GetKey(ctx context.Context, id string) (entity *entity.Entity, err error) {
-	if isFingerprint(id) {
-		addr := "https://keys.openpgp.org/vks/v1/by-fingerprint/" + strings.ToUpper(id)
-		return getEntityHTTP(ctx, addr, true)
-	} else if email, err := mail.ParseAddress(id); err == nil {
-		addr, advAddr := getWKDPubKeyAddr(email)
-		req, err := getEntityHTTP(ctx, addr, false)
-		if err == nil {
-			return req, err
-		}
-
-		req, err = getEntityHTTP(ctx, advAddr, false)
-		if err == nil {
-			return req, err
-		}
-
-		addr = "https://keys.openpgp.org/vks/v1/by-email/" + url.QueryEscape(id)
-		return getEntityHTTP(ctx, addr, true)
-	} else {
-		return entity, fmt.Errorf("Parse address: %w", err)
-	}
+// GetKey looks up the key for an email address, key id or fingerprint from the default sources.
+func GetKey(ctx context.Context, id string) (*entity.Entity, error) {
+	return Parallel{DefaultSources, DefaultWait}.GetKey(ctx, id)
 }
 
-func getEntityHTTP(ctx context.Context, url string, useArmored bool) (entity *entity.Entity, err error) {
+// getEntityHTTP fetches the key at url. Only a key that matches the id of the
+// kind is accepted, the first key is used if kind is 0. A key fetched before is requested with its
+// validators and reused if the remote reports it is not modified.
+func getEntityHTTP(ctx context.Context, url string, useArmored bool, kind IDKind, id string) (entity *entity.Entity, err error) {
 	log := log.Ctx(ctx)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return entity, err
 	}
-	cl := http.Client{}
-	resp, err := cl.Do(req)
+	key := keyCacheKey(url, id)
+	cached := setConditional(req, key)
+
+	resp, err := httpClient.Do(req)
 	if err != nil {
 		return entity, fmt.Errorf("Requesting key: %w\nRemote URL: %v", err, url)
 	}
+	defer resp.Body.Close()
+
 	log.Debug().
 		Bool("useArmored", useArmored).
 		Str("status", resp.Status).
 		Str("url", url).
 		Msg("getEntityHTTP")
 
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		return copyEntity(cached.entity), nil
+	}
 	if resp.StatusCode != 200 {
 		return entity, fmt.Errorf("bad response from remote: %s\nRemote URL: %v", resp.Status, url)
 	}
 
-	defer resp.Body.Close()
-
 	if resp.Header.Get("Content-Type") == "application/pgp-keys" {
 		useArmored = true
 	}
 
-	return ReadKey(resp.Body, useArmored)
+	entity, err = readKeyMatching(resp.Body, useArmored, kind, id)
+	if err != nil {
+		return entity, err
+	}
+	storeKey(key, resp, entity)
+
+	return copyEntity(entity), nil
 }
 
-func ReadKey(r io.Reader, useArmored bool) (e *entity.Entity, err error) {
-	var buf bytes.Buffer
+// checkHTTP requests url and fails unless it responds with 200.
+func checkHTTP(ctx context.Context, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
 
-	var w io.Writer = &buf
-	e = &entity.Entity{}
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("bad response from remote: %s\nRemote URL: %v", resp.Status, url)
+	}
+	return nil
+}
 
-	defer func() {
-		if e != nil {
-			e.ArmorText = buf.String()
-		}
-	}()
+var ErrNoMatchingUID = errors.New("no key with a matching user id")
+var ErrNoMatchingKey = errors.New("no key with a matching key id or fingerprint")
 
-	if !useArmored {
-		var aw io.WriteCloser
-		aw, err = armor.Encode(&buf, "PGP PUBLIC KEY BLOCK", nil)
-		if err != nil {
-			return e, fmt.Errorf("Read key: %w", err)
-		}
-		defer aw.Close()
+func ReadKey(r io.Reader, useArmored bool) (e *entity.Entity, err error) {
+	return ReadKeyFor(r, useArmored, "")
+}
 
-		w = aw
+// ReadKeyFor reads the first key that has a user id for the email address. If
+// email is empty the first key is read.
+func ReadKeyFor(r io.Reader, useArmored bool, email string) (*entity.Entity, error) {
+	if email == "" {
+		return readKeyMatching(r, useArmored, 0, "")
 	}
+	return readKeyMatching(r, useArmored, IDEmail, email)
+}
 
-	r = io.TeeReader(r, w)
+// readKeyMatching reads the first key that matches the id of the kind, or the
+// first key if kind is 0. Servers may answer with other keys than asked for.
+func readKeyMatching(r io.Reader, useArmored bool, kind IDKind, id string) (*entity.Entity, error) {
+	lis, err := ReadKeys(r, useArmored)
+	if err != nil {
+		return nil, err
+	}
 
-	var lis openpgp.EntityList
+	for _, e := range lis {
+		if kind == 0 || matchEntity(e, kind, id) {
+			return e, nil
+		}
+	}
 
+	if kind == IDEmail {
+		return nil, ErrNoMatchingUID
+	}
+	return nil, ErrNoMatchingKey
+}
+
+// ReadKeys reads every key in r. Like openpgp.ReadKeyRing keys that can not be
+// parsed are skipped. The armor of each key holds only its own packets.
+func ReadKeys(r io.Reader, useArmored bool) ([]*entity.Entity, error) {
 	if useArmored {
-		lis, err = openpgp.ReadArmoredKeyRing(r)
-	} else {
-		lis, err = openpgp.ReadKeyRing(r)
+		block, err := armor.Decode(r)
+		if err != nil {
+			return nil, fmt.Errorf("Read key: %w", err)
+		}
+		if block.Type != openpgp.PublicKeyType {
+			return nil, fmt.Errorf("Read key: expected public key block, got: %s", block.Type)
+		}
+		r = block.Body
 	}
+
+	data, err := ioutil.ReadAll(r)
 	if err != nil {
-		return e, fmt.Errorf("Read key: %w", err)
+		return nil, fmt.Errorf("Read key: %w", err)
 	}
 
-	e, err = entity.GetOne(lis)
+	keys, err := entity.SplitKeys(data)
 	if err != nil {
-		return e, fmt.Errorf("Parse key: %w", err)
+		return nil, fmt.Errorf("Read key: %w", err)
 	}
 
-	return
-}
+	var lis []*entity.Entity
+	var lastErr error
+	for _, key := range keys {
+		e, err := entity.Read(key)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		if e.ArmorText, err = armorKey(key); err != nil {
+			return nil, fmt.Errorf("Read key: %w", err)
+		}
+		lis = append(lis, e)
+	}
 
-func isFingerprint(s string) bool {
-	for _, r := range s {
-		switch r {
-		case '0', '1', '2', '3', '4', '5', '6', '7', '8', '9', 'a', 'b', 'c', 'd', 'e', 'f', 'A', 'B', 'C', 'D', 'E', 'F':
-		default:
-			return false
+	if len(lis) == 0 {
+		if lastErr == nil {
+			lastErr = ErrNotFound
 		}
+		return nil, fmt.Errorf("Parse key: %w", lastErr)
 	}
 
-	return true
+	return lis, nil
 }
 
-func getWKDPubKeyAddr(email *mail.Address) (string, string) {
+// wkdAddr returns the key and policy urls for email using the advanced or direct
+// method. The local part is mapped to lower case before hashing.
+func wkdAddr(email *mail.Address, advanced bool) (key, policy string) {
 	parts := strings.SplitN(email.Address, "@", 2)
-	hash := sha1.Sum([]byte(parts[0]))
+	domain := strings.ToLower(parts[1])
+	hash := sha1.Sum([]byte(strings.ToLower(parts[0])))
 	lp := zbase32.EncodeToString(hash[:])
+	l := url.QueryEscape(parts[0])
+
+	if advanced {
+		base := fmt.Sprintf("https://openpgpkey.%s/.well-known/openpgpkey/%s", domain, domain)
+		return fmt.Sprintf("%s/hu/%s?l=%s", base, lp, l), base + "/policy"
+	}
+
+	base := fmt.Sprintf("https://%s/.well-known/openpgpkey", domain)
+	return fmt.Sprintf("%s/hu/%s?l=%s", base, lp, l), base + "/policy"
+}
+
+func armorKey(key []byte) (string, error) {
+	var buf bytes.Buffer
+	aw, err := armor.Encode(&buf, openpgp.PublicKeyType, nil)
+	if err != nil {
+		return "", err
+	}
+	if _, err = aw.Write(key); err != nil {
+		return "", err
+	}
+	if err = aw.Close(); err != nil {
+		return "", err
+	}
 
-	return fmt.Sprintf("https://%s/.well-known/openpgpkey/hu/%s", parts[1], lp),
-		fmt.Sprintf("https://openpgpkey.%s/.well-known/openpgpkey/hu/%s/%s", parts[1], parts[1], lp)
+	return buf.String(), nil
 }
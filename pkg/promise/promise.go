@@ -44,6 +44,7 @@ type qTask struct {
 
 	cancel func()
 	done   chan struct{}
+	once   sync.Once
 
 	result interface{}
 	err    error
@@ -81,13 +82,10 @@ func (t *qTask) Result() interface{} { return t.result }
 func (t *qTask) Err() error          { return t.err }
 
 func (t *qTask) finish() {
-	if t.done == nil {
-		return
-	}
-
-	t.cancel()
-	close(t.done)
-	t.done = nil
+	t.once.Do(func() {
+		t.cancel()
+		close(t.done)
+	})
 }
 
 type Option interface {